@@ -1,108 +1,601 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
+	"time"
 
 	audit "github.com/krakend/krakend-audit"
+	"github.com/krakend/krakend-cobra/v2/explain"
+	"github.com/krakend/krakend-cobra/v2/history"
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/report"
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 const (
-	defaultFormatTmpl  = "{{ range .Recommendations }}{{.Rule}}\t[{{.Severity}}]   \t{{.Message}}\n{{ end }}"
-	terminalFormatTmpl = "{{ range .Recommendations }}{{.Rule}}\t[{{colored .Severity}}]   \t{{.Message}}\n{{ end }}"
+	defaultFormatTmpl  = `{{ define "severity" }}{{ . }}{{ end }}` + reportFormatTmpl
+	terminalFormatTmpl = `{{ define "severity" }}{{ colored . }}{{ end }}` + reportFormatTmpl
+
+	jsonFormatTmpl = "{{ marshal . }}\n"
+
+	// reportFormatTmpl renders the report of the configuration file, or the report of
+	// every file followed by the totals when auditing several of them
+	reportFormatTmpl = "{{ if .Files }}{{ range .Files }}==> {{.File}}\n{{ if .Error }}ERROR {{.Error}}\n{{ else }}{{ template \"report\" . }}{{ end }}\n{{ end }}" +
+		"{{ with .Summary }}Total of {{.Files}} files: {{ template \"summary\" . }}{{ end }}{{ else }}{{ template \"report\" . }}{{ end }}" +
+		recommendationsFormatTmpl + summaryFormatTmpl
+
+	recommendationsFormatTmpl = "{{ define \"report\" }}{{ range .Recommendations }}{{.Rule}}\t[{{ template \"severity\" .Severity }}]   \t{{.Message}}{{ with .Position }} ({{.}}){{ end }}\n{{ end }}" +
+		"{{ if .Suppressed }}\nSuppressed:\n{{ range .Suppressed }}{{.Rule}}\t[{{.Severity}}]   \t{{.Message}}{{ with .Position }} ({{.}}){{ end }}\n\t\t{{.Reason}}" +
+		"{{ if .Owner }} ({{.Owner}}){{ end }}{{ if .Expires }}, until {{.Expires}}{{ end }} [{{.Source}}]\n{{ end }}{{ end }}" +
+		"{{ with .Summary }}\n{{ template \"summary\" . }}{{ end }}" + deltaFormatTmpl + "{{ range .Explanations }}\n{{ template \"explanation\" . }}{{ end }}{{ end }}" +
+		explanationFormatTmpl
+
+	explanationFormatTmpl = "{{ define \"explanation\" }}{{.Rule}}: {{ default \"no explanation available\" .Title }}\n" +
+		"{{ with .Rationale }}{{ indent 2 . }}\n{{ end }}{{ range .Links }}  {{.}}\n{{ end }}" +
+		"{{ range .Remediations }}\n  {{ with .Location }}{{.}}{{ else }}service{{ end }}:\n{{ indent 4 .Snippet }}\n{{ end }}{{ end }}"
+
+	deltaFormatTmpl = "{{ with .Delta }}Since {{ .Previous.Format \"2006-01-02 15:04:05\" }}: {{ len .New }} new, {{ len .Fixed }} fixed, {{ len .Unchanged }} unchanged. " +
+		"Score {{.PreviousScore}} -> {{.Score}} ({{ printf \"%+d\" .ScoreChange }}){{ if .ConfigChanged }}, configuration changed{{ end }}\n" +
+		"{{ range .New }}+ {{.Rule}}\t[{{.Severity}}]   \t{{.Message}}\n{{ end }}{{ range .Fixed }}- {{.Rule}}\t[{{.Severity}}]   \t{{.Message}}\n{{ end }}{{ end }}"
+
+	summaryFormatTmpl = "{{ define \"summary\" }}{{.Total}} recommendation(s): {{.Critical}} CRITICAL, {{.High}} HIGH, {{.Medium}} MEDIUM, {{.Low}} LOW" +
+		"{{ if .Suppressed }}, {{.Suppressed}} suppressed{{ end }}{{ if .Hidden }}, {{.Hidden}} hidden by severity{{ end }}{{ if .Errors }}, {{.Errors}} file(s) with errors{{ end }}. Score: {{.Score}}/100" +
+		"{{ if .FailOn }}. Failing on {{.FailOn}} or higher: {{ if .Failed }}FAILED{{ else }}PASSED{{ end }}{{ end }}\n{{ end }}"
 )
 
-func auditFunc(cmd *cobra.Command, _ []string) {
-	if cfgFile == "" {
-		cmd.Println(errorMsg("Please, provide the path to the configuration file with --config or see all the options with --help"))
+// auditExitCodes are the exit codes of the audit command when --fail-on is set, by the
+// highest severity found
+var auditExitCodes = map[string]int{
+	audit.SeverityLow:      2,
+	audit.SeverityMedium:   3,
+	audit.SeverityHigh:     4,
+	audit.SeverityCritical: 5,
+}
+
+// auditSummary counts the recommendations by severity
+type auditSummary struct {
+	Total      int `json:"total"`
+	Critical   int `json:"critical"`
+	High       int `json:"high"`
+	Medium     int `json:"medium"`
+	Low        int `json:"low"`
+	Suppressed int `json:"suppressed"`
+	// Hidden counts the recommendations not rendered because of their severity
+	Hidden  int    `json:"hidden,omitempty"`
+	Highest string `json:"highest,omitempty"`
+	Score   int    `json:"score"`
+	FailOn  string `json:"fail_on,omitempty"`
+	Failed  bool   `json:"failed"`
+	// Files and Errors count the configuration files audited and the ones that could
+	// not be audited when auditing several of them
+	Files  int `json:"files,omitempty"`
+	Errors int `json:"errors,omitempty"`
+}
+
+// newAuditSummary summarizes all the recommendations, including the ones not shown
+// because of their severity
+func newAuditSummary(recommendations []auditRecommendation, shown, suppressed int, failOn string) auditSummary {
+	s := auditSummary{Total: len(recommendations), Suppressed: suppressed, Hidden: len(recommendations) - shown, FailOn: failOn, Score: report.MaxScore}
+	for _, r := range recommendations {
+		switch r.Severity {
+		case audit.SeverityCritical:
+			s.Critical++
+		case audit.SeverityHigh:
+			s.High++
+		case audit.SeverityMedium:
+			s.Medium++
+		case audit.SeverityLow:
+			s.Low++
+		}
+		if s.Highest == "" || severityRank(r.Severity) < severityRank(s.Highest) {
+			s.Highest = r.Severity
+		}
+	}
+	// without --fail-on, the audit fails as it always did: when showing some
+	// recommendation
+	if failOn == "" {
+		s.Failed = shown > 0
+	} else {
+		s.Failed = s.Highest != "" && severityRank(s.Highest) <= severityRank(failOn)
+	}
+	return s
+}
+
+// exitCode returns the exit code of the audit: 1 when some file could not be audited
+// or when failing without --fail-on, and the code of the highest severity found
+// otherwise
+func (s auditSummary) exitCode() int {
+	switch {
+	case s.Errors > 0:
+		return 1
+	case !s.Failed:
+		return 0
+	case s.FailOn == "":
+		return 1
+	default:
+		return auditExitCodes[s.Highest]
+	}
+}
+
+// severityRank returns the position of the severity, where 0 is the most severe one
+func severityRank(severity string) int {
+	if i := slices.Index(policy.Severities, severity); i >= 0 {
+		return i
+	}
+	return len(policy.Severities)
+}
+
+// auditReport is the data rendered by the audit templates
+type auditReport struct {
+	Recommendations []auditRecommendation `json:"recommendations"`
+	// all holds the recommendations of every severity, including the ones filtered
+	// out of Recommendations by --severity
+	all        []auditRecommendation
+	Suppressed []policy.Suppressed `json:"suppressed"`
+	Summary    auditSummary        `json:"summary"`
+	// Explanations describe the rules of the recommendations when --explain is set
+	Explanations []explain.Rendered `json:"explanations,omitempty"`
+	// Delta holds the changes since the previous run recorded with --history
+	Delta *history.Delta `json:"delta,omitempty"`
+	// Files are the reports of every configuration file when auditing several of
+	// them. The rest of fields aggregate all of them.
+	Files []auditFileReport `json:"files,omitempty"`
+
+	hash string
+}
+
+// auditRecommendation is a recommendation with the position in the source of the
+// element it refers to
+type auditRecommendation struct {
+	audit.Recommendation
+	Position *source.Position `json:"position,omitempty"`
+	// Candidates are the elements a built-in recommendation probably refers to
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// auditFileReport is the report of one of the configuration files audited
+type auditFileReport struct {
+	File  string `json:"file"`
+	Error string `json:"error,omitempty"`
+	auditReport
+}
+
+// auditOptions are the settings shared by the audits of all the configuration files
+type auditOptions struct {
+	ignore       []string
+	severities   []string
+	suppressions []policy.Suppression
+	rules        []policy.Rule
+}
+
+func auditFunc(cmd *cobra.Command, args []string) {
+	paths, err := configPaths(args)
+	if err != nil {
+		cmd.PrintErrln(errorMsg("ERROR finding the configuration files:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+	if len(paths) == 0 {
+		cmd.PrintErrln(errorMsg("Please, provide the path to the configuration file with --config or see all the options with --help"))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	auditFailOn = strings.ToUpper(auditFailOn)
+	if _, ok := auditExitCodes[auditFailOn]; auditFailOn != "" && !ok {
+		cmd.PrintErrln(errorMsg("Please, provide a valid severity to --fail-on: CRITICAL, HIGH, MEDIUM or LOW"))
 		os.Exit(1) // skipcq: RVV-A0003
 		return
 	}
 
-	cfg, err := parser.Parse(cfgFile)
+	outputs, err := auditOutputs(cmd)
 	if err != nil {
-		cmd.Println(errorMsg("ERROR parsing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		cmd.PrintErrln(errorMsg("ERROR parsing the template:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	opts, label, err := newAuditOptions()
+	if err != nil {
+		cmd.PrintErrln(errorMsg(label) + fmt.Sprintf("\t%s\n", err.Error()))
 		os.Exit(1) // skipcq: RVV-A0003
 		return
 	}
-	cfg.Normalize()
 
-	if formatTmpl == "" {
-		if IsTTY {
-			formatTmpl = terminalFormatTmpl
-		} else {
-			formatTmpl = defaultFormatTmpl
+	var data auditReport
+	if len(paths) == 1 {
+		data, label, err = auditConfig(paths[0], opts)
+		if err != nil {
+			cmd.PrintErrln(errorMsg(label) + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
 		}
+	} else {
+		files := forEachConfig(paths, func(path string) auditFileReport {
+			r, label, err := auditConfig(path, opts)
+			if err != nil {
+				return auditFileReport{File: path, Error: strings.TrimPrefix(label, "ERROR ") + " " + err.Error()}
+			}
+			return auditFileReport{File: path, auditReport: r}
+		})
+		data = mergeAuditReports(files)
+		for _, f := range files {
+			if f.Error != "" {
+				cmd.PrintErrln(errorMsg("ERROR auditing "+f.File+":") + fmt.Sprintf("\t%s\n", f.Error))
+			}
+		}
+	}
+
+	if auditHistoryDir != "" {
+		if err := recordAuditHistory(auditHistoryDir, paths[0], &data, time.Now()); err != nil {
+			cmd.PrintErrln(errorMsg("ERROR recording the audit history:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+	}
+
+	for _, o := range outputs {
+		if err := o.write(cmd, data); err != nil {
+			cmd.PrintErrln(errorMsg("ERROR rendering the results:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+	}
+
+	if code := data.Summary.exitCode(); code != 0 {
+		os.Exit(code) // skipcq: RVV-A0003
 	}
+}
 
+// newAuditOptions loads the settings of the audits from the flags, returning the label
+// of the error to report when it fails
+func newAuditOptions() (auditOptions, string, error) {
 	severitiesToInclude = strings.ReplaceAll(severitiesToInclude, " ", "")
-	rules := strings.Split(strings.ReplaceAll(rulesToExclude, " ", ""), ",")
+	opts := auditOptions{
+		ignore:     strings.Split(strings.ReplaceAll(rulesToExclude, " ", ""), ","),
+		severities: strings.Split(severitiesToInclude, ","),
+	}
 
+	var err error
 	if rulesToExcludePath != "" {
-		b, err := os.ReadFile(rulesToExcludePath)
+		switch strings.ToLower(filepath.Ext(rulesToExcludePath)) {
+		case ".json", ".yml", ".yaml":
+			opts.suppressions, err = policy.LoadSuppressions(rulesToExcludePath)
+			if err != nil {
+				return opts, "ERROR loading the suppressions:", err
+			}
+		default:
+			b, err := os.ReadFile(rulesToExcludePath)
+			if err != nil {
+				return opts, "ERROR accessing the ignore file:", err
+			}
+			for _, line := range strings.Split(strings.ReplaceAll(string(b), " ", ""), "\n") {
+				if line == "" {
+					continue
+				}
+				opts.ignore = append(opts.ignore, line)
+			}
+		}
+	}
+
+	if auditRulesPath != "" {
+		opts.rules, err = policy.Load(auditRulesPath)
 		if err != nil {
-			cmd.Println(errorMsg("ERROR accessing the ignore file:") + fmt.Sprintf("\t%s\n", err.Error()))
-			os.Exit(1) // skipcq: RVV-A0003
-			return
+			return opts, "ERROR loading the audit rules:", err
 		}
-		for _, line := range strings.Split(strings.ReplaceAll(string(b), " ", ""), "\n") {
-			if line == "" {
-				continue
+		for _, r := range opts.rules {
+			if r.URL != "" {
+				report.RuleURLs[r.ID] = r.URL
 			}
-			rules = append(rules, line)
 		}
 	}
+	return opts, "", nil
+}
 
-	result, err := audit.Audit(
-		&cfg,
-		rules,
-		strings.Split(severitiesToInclude, ","),
-	)
+// auditConfig audits the configuration file, returning the label of the error to
+// report when it fails
+func auditConfig(path string, opts auditOptions) (auditReport, string, error) {
+	content, err := os.ReadFile(path)
 	if err != nil {
-		cmd.Println(errorMsg("ERROR auditing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
-		os.Exit(1) // skipcq: RVV-A0003
-		return
+		return auditReport{}, "ERROR reading the configuration file:", err
 	}
 
-	funcMap := template.FuncMap{
-		"marshal": func(v interface{}) string {
-			a, _ := json.Marshal(v)
-			return string(a)
-		},
-		"colored": func(s string) string {
-			switch s {
-			case audit.SeverityLow:
-				return fmt.Sprintf("\033[32;1m%s\033[0m", s)
-			case audit.SeverityMedium:
-				return fmt.Sprintf("\033[33;1m%s\033[0m", s)
-			case audit.SeverityHigh:
-				return fmt.Sprintf("\033[31;1m%s\033[0m", s)
-			case audit.SeverityCritical:
-				return fmt.Sprintf("\033[41;1m%s\033[0m", s)
-			default:
-				return s
-			}
-		},
+	p := configParser()
+	cfg, err := p.Parse(path)
+	if err != nil {
+		return auditReport{}, "ERROR parsing the configuration file:", err
 	}
+	sm := newSourceMap(p, path)
+
+	data, label, err := auditService(cfg, sm, opts)
+	data.hash = history.Hash(content)
+	return data, label, err
+}
+
+// auditService audits the parsed configuration, locating the findings with the source
+// map
+func auditService(cfg config.ServiceConfig, sm sourceMap, opts auditOptions) (auditReport, string, error) {
+	cfg.Normalize()
 
-	tmpl, err := template.New("audit").Funcs(funcMap).Parse(formatTmpl)
+	inline, err := policy.InlineSuppressions(cfg)
 	if err != nil {
-		cmd.Println(errorMsg("ERROR parsing the template:") + fmt.Sprintf("\t%s\n", err.Error()))
-		os.Exit(1) // skipcq: RVV-A0003
-		return
+		return auditReport{}, "ERROR loading the suppressions:", err
 	}
+	suppressions := append(slices.Clone(opts.suppressions), inline...)
 
-	if err := tmpl.Execute(os.Stderr, result); err != nil {
-		cmd.Println(errorMsg("ERROR rendering the results:") + fmt.Sprintf("\t%s\n", err.Error()))
-		os.Exit(1) // skipcq: RVV-A0003
-		return
+	// every severity is evaluated, so --severity only filters the recommendations
+	// rendered and not the summary, the score nor the exit code
+	result, err := audit.Audit(&cfg, opts.ignore, policy.Severities)
+	if err != nil {
+		return auditReport{}, "ERROR auditing the configuration file:", err
 	}
 
-	if len(result.Recommendations) > 0 {
-		os.Exit(1) // skipcq: RVV-A0003
+	if err := checkScopedSuppressions(suppressions, opts.rules); err != nil {
+		return auditReport{}, "ERROR loading the suppressions:", err
+	}
+
+	findings := make([]policy.Finding, 0, len(result.Recommendations))
+	for _, r := range result.Recommendations {
+		findings = append(findings, builtinFinding(&cfg, r))
+	}
+	for _, f := range policy.Evaluate(cfg, opts.rules) {
+		if slices.Contains(opts.ignore, f.Rule) {
+			continue
+		}
+		findings = append(findings, f)
+	}
+	for i := range findings {
+		pos := sm.finding(cfg, findings[i], opts.rules)
+		findings[i].Position = &pos
+	}
+
+	kept, suppressed := policy.Suppress(findings, suppressions, time.Now())
+	data := auditReport{Suppressed: suppressed}
+	var shown []policy.Finding
+	for _, f := range kept {
+		r := auditRecommendation{
+			Recommendation: audit.Recommendation{
+				Rule:     f.Rule,
+				Severity: f.Severity,
+				Message:  f.Message,
+			},
+			Position:   f.Position,
+			Candidates: f.Candidates,
+		}
+		data.all = append(data.all, r)
+		if slices.Contains(opts.severities, f.Severity) {
+			data.Recommendations = append(data.Recommendations, r)
+			shown = append(shown, f)
+		}
+	}
+	data.Explanations, err = auditExplanations(&cfg, shown, opts.rules)
+	if err != nil {
+		return auditReport{}, "ERROR explaining the recommendations:", err
+	}
+	data.Summary = newAuditSummary(data.all, len(data.Recommendations), len(suppressed), auditFailOn)
+	data.Summary.Score = auditScore(data.all, cfg)
+	return data, "", nil
+}
+
+// auditScore rates all the recommendations of the configuration, relative to its
+// number of endpoints, backends and async agents
+func auditScore(recommendations []auditRecommendation, cfg config.ServiceConfig) int {
+	recs := make([]audit.Recommendation, len(recommendations))
+	for i, r := range recommendations {
+		recs[i] = r.Recommendation
+	}
+	elements := 1
+	for _, e := range cfg.Endpoints {
+		elements += 1 + len(e.Backend)
+	}
+	for _, a := range cfg.AsyncAgents {
+		elements += 1 + len(a.Backend)
+	}
+	return report.Score(recs, elements)
+}
+
+// builtinFinding returns the finding of a recommendation of the built-in audit. The
+// audit does not report the elements violating the rules, so the ones of the Catalog
+// get the elements in their scope needing the remediation as candidates, leaving the
+// counts and the exit code as the audit reports them.
+func builtinFinding(cfg *config.ServiceConfig, r audit.Recommendation) policy.Finding {
+	locations, _ := explain.Locations(cfg, r.Rule)
+	return policy.Finding{Rule: r.Rule, Severity: r.Severity, Message: r.Message, Candidates: locations}
+}
+
+// checkScopedSuppressions rejects the suppressions of some elements targeting built-in
+// rules that cannot be located, as they would never apply
+func checkScopedSuppressions(suppressions []policy.Suppression, rules []policy.Rule) error {
+	for _, s := range suppressions {
+		if !s.Scoped() || slices.ContainsFunc(rules, func(r policy.Rule) bool { return r.ID == s.Rule }) {
+			continue
+		}
+		if _, ok := explain.Locations(&config.ServiceConfig{}, s.Rule); !ok {
+			return fmt.Errorf("%s: the findings of the rule %s are not located in an element, suppress them without selector in a suppressions file", s.Source, s.Rule)
+		}
 	}
+	return nil
+}
+
+// auditExplanations explains the rules of the findings when --explain is set, with the
+// remediations for the elements reported
+func auditExplanations(cfg *config.ServiceConfig, findings []policy.Finding, rules []policy.Rule) ([]explain.Rendered, error) {
+	if !auditExplain {
+		return nil, nil
+	}
+	var ids []string
+	locations := map[string][]string{}
+	messages := map[string]string{}
+	for _, f := range findings {
+		if !slices.Contains(ids, f.Rule) {
+			ids = append(ids, f.Rule)
+			messages[f.Rule] = f.Message
+		}
+		if f.Location != "" {
+			locations[f.Rule] = append(locations[f.Rule], f.Location)
+		}
+		locations[f.Rule] = append(locations[f.Rule], f.Candidates...)
+	}
+
+	res := make([]explain.Rendered, 0, len(ids))
+	for _, id := range ids {
+		e, ok := explain.Lookup(id, rules)
+		if !ok {
+			e = explain.Fallback(id, messages[id])
+		}
+		r, err := e.Render(cfg, locations[id])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// mergeAuditReports aggregates the reports of several files. The messages of the
+// aggregated recommendations are prefixed with the file they belong to.
+func mergeAuditReports(files []auditFileReport) auditReport {
+	data := auditReport{Files: files}
+	errs, score := 0, 0
+	for _, f := range files {
+		if f.Error != "" {
+			errs++
+			continue
+		}
+		score += f.Summary.Score
+		for _, r := range f.Recommendations {
+			r.Message = f.File + ": " + r.Message
+			data.Recommendations = append(data.Recommendations, r)
+		}
+		for _, r := range f.all {
+			r.Message = f.File + ": " + r.Message
+			data.all = append(data.all, r)
+		}
+		for _, s := range f.Suppressed {
+			s.Message = f.File + ": " + s.Message
+			data.Suppressed = append(data.Suppressed, s)
+		}
+	}
+	data.Summary = newAuditSummary(data.all, len(data.Recommendations), len(data.Suppressed), auditFailOn)
+	data.Summary.Files = len(files)
+	data.Summary.Errors = errs
+	// the score of several files is the average of their scores
+	if audited := len(files) - errs; audited > 0 {
+		data.Summary.Score = int(math.Round(float64(score) / float64(audited)))
+	}
+	return data
+}
+
+// recordAuditHistory stores the results of the run in the history directory, and
+// attaches to the reports the changes since the previous run
+func recordAuditHistory(dir, path string, data *auditReport, now time.Time) error {
+	prev, err := history.Latest(dir)
+	if err != nil {
+		return err
+	}
+
+	snapshot := history.Snapshot{Timestamp: now}
+	record := func(name string, r *auditReport) {
+		f := history.File{File: name, Hash: r.hash, Score: r.Summary.Score, Findings: []history.Finding{}}
+		// the snapshots keep the recommendations hidden by --severity, so changing it
+		// between runs does not report them as fixed
+		for _, rec := range r.all {
+			f.Findings = append(f.Findings, history.Finding{Rule: rec.Rule, Severity: rec.Severity, Message: rec.Message})
+		}
+		snapshot.Files = append(snapshot.Files, f)
+
+		if prev == nil {
+			return
+		}
+		if p, ok := prev.File(name); ok {
+			d := history.Compare(prev.Timestamp, p, f)
+			r.Delta = &d
+		}
+	}
+
+	if len(data.Files) == 0 {
+		record(path, data)
+	}
+	for i := range data.Files {
+		if data.Files[i].Error == "" {
+			record(data.Files[i].File, &data.Files[i].auditReport)
+		}
+	}
+
+	_, err = history.Save(dir, snapshot)
+	return err
+}
+
+// auditOutput is a destination of the audit report
+type auditOutput struct {
+	// path of the file, or - for the standard output of the command
+	path string
+	tmpl *template.Template
+}
+
+// auditOutputs returns the destinations of the report. The files with the .json, .md
+// and .html extensions get the report in that format, and the rest of them, as well as
+// the standard output, use the --format template. The default template is colored
+// when the standard output is a terminal.
+func auditOutputs(cmd *cobra.Command) ([]auditOutput, error) {
+	paths := auditOutputFiles
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	outputs := make([]auditOutput, len(paths))
+	for i, path := range paths {
+		spec := formatTmpl
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			spec = jsonFormatTmpl
+		case ".md", ".markdown":
+			spec = "markdown"
+		case ".html", ".htm":
+			spec = "html"
+		}
+		if spec == "" {
+			spec = defaultFormatTmpl
+			if path == "-" && isTerminal(cmd.OutOrStdout()) {
+				spec = terminalFormatTmpl
+			}
+		}
+
+		tmpl, err := report.New(spec)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = auditOutput{path: path, tmpl: tmpl}
+	}
+	return outputs, nil
+}
+
+func (o auditOutput) write(cmd *cobra.Command, data auditReport) error {
+	if o.path == "-" {
+		return o.tmpl.Execute(cmd.OutOrStdout(), data)
+	}
+	f, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+	if err := o.tmpl.Execute(f, data); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", o.path, err)
+	}
+	return f.Close()
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isatty.IsTerminal(f.Fd())
 }
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	audit "github.com/krakend/krakend-audit"
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_auditSummary(t *testing.T) {
+	recommendations := []auditRecommendation{
+		{Recommendation: audit.Recommendation{Rule: "1", Severity: audit.SeverityLow}},
+		{Recommendation: audit.Recommendation{Rule: "2", Severity: audit.SeverityHigh}},
+		{Recommendation: audit.Recommendation{Rule: "3", Severity: audit.SeverityMedium}},
+		{Recommendation: audit.Recommendation{Rule: "4", Severity: audit.SeverityLow}},
+	}
+
+	for name, tc := range map[string]struct {
+		recommendations []auditRecommendation
+		hidden          int
+		failOn          string
+		failed          bool
+		exitCode        int
+	}{
+		"no fail-on":             {recommendations: recommendations, failed: true, exitCode: 1},
+		"no fail-on, no results": {},
+		"below the threshold":    {recommendations: recommendations, failOn: audit.SeverityCritical},
+		"at the threshold":       {recommendations: recommendations, failOn: audit.SeverityHigh, failed: true, exitCode: 4},
+		"above the threshold":    {recommendations: recommendations, failOn: audit.SeverityLow, failed: true, exitCode: 4},
+		"only low":               {recommendations: recommendations[:1], failOn: audit.SeverityLow, failed: true, exitCode: 2},
+		"hidden, no fail-on":     {recommendations: recommendations, hidden: 4},
+		"hidden, at threshold":   {recommendations: recommendations, hidden: 4, failOn: audit.SeverityHigh, failed: true, exitCode: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newAuditSummary(tc.recommendations, len(tc.recommendations)-tc.hidden, 0, tc.failOn)
+			require.Equal(t, tc.hidden, s.Hidden)
+			require.Equal(t, tc.failed, s.Failed)
+			require.Equal(t, tc.exitCode, s.exitCode())
+		})
+	}
+
+	s := newAuditSummary(recommendations, 4, 3, "")
+	require.Equal(t, auditSummary{Total: 4, High: 1, Medium: 1, Low: 2, Suppressed: 3, Highest: audit.SeverityHigh, Score: 100, Failed: true}, s)
+}
+
+func Test_auditOutputs(t *testing.T) {
+	dir := t.TempDir()
+	defer func(files []string, tmpl string) {
+		auditOutputFiles = files
+		formatTmpl = tmpl
+	}(auditOutputFiles, formatTmpl)
+
+	auditOutputFiles = []string{"-", filepath.Join(dir, "report.json"), filepath.Join(dir, "report.txt")}
+	formatTmpl = "{{ range .Recommendations }}{{ .Rule }} {{ end }}"
+
+	var stdout, stderr bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+
+	data := auditReport{Recommendations: []auditRecommendation{{
+		Recommendation: audit.Recommendation{Rule: "1.1.1", Severity: audit.SeverityHigh, Message: "m"},
+		Position:       &source.Position{File: "krakend.json", Line: 12, Column: 5},
+	}}}
+	data.Summary = newAuditSummary(data.Recommendations, 1, 0, "")
+
+	outputs, err := auditOutputs(cmd)
+	require.NoError(t, err)
+	for _, o := range outputs {
+		require.NoError(t, o.write(cmd, data))
+	}
+
+	require.Equal(t, "1.1.1 ", stdout.String())
+	require.Empty(t, stderr.String())
+
+	b, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"recommendations":[{"rule":"1.1.1","severity":"HIGH","message":"m","position":{"file":"krakend.json","line":12,"column":5}}],"suppressed":null,`+
+		`"summary":{"total":1,"critical":0,"high":1,"medium":0,"low":0,"suppressed":0,"highest":"HIGH","score":100,"failed":true}}`, string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "report.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "1.1.1 ", string(b))
+}
+
+func Test_mergeAuditReports(t *testing.T) {
+	a := auditReport{Recommendations: []auditRecommendation{{Recommendation: audit.Recommendation{Rule: "1.1.1", Severity: audit.SeverityLow, Message: "m"}}}}
+	b := auditReport{Recommendations: []auditRecommendation{{Recommendation: audit.Recommendation{Rule: "2.1.1", Severity: audit.SeverityCritical, Message: "n"}}}}
+	a.all, b.all = a.Recommendations, b.Recommendations
+	a.Summary = newAuditSummary(a.all, 1, 0, "")
+	b.Summary = newAuditSummary(b.all, 1, 0, "")
+	a.Summary.Score = auditScore(a.all, config.ServiceConfig{})
+	b.Summary.Score = auditScore(b.all, config.ServiceConfig{})
+	require.Equal(t, 91, a.Summary.Score)
+	require.Equal(t, 50, b.Summary.Score)
+
+	data := mergeAuditReports([]auditFileReport{
+		{File: "a.json", auditReport: a},
+		{File: "b.json", auditReport: b},
+		{File: "c.json", Error: "parsing the configuration file: boom"},
+	})
+
+	require.Equal(t, []auditRecommendation{
+		{Recommendation: audit.Recommendation{Rule: "1.1.1", Severity: audit.SeverityLow, Message: "a.json: m"}},
+		{Recommendation: audit.Recommendation{Rule: "2.1.1", Severity: audit.SeverityCritical, Message: "b.json: n"}},
+	}, data.Recommendations)
+	require.Equal(t, auditSummary{Total: 2, Critical: 1, Low: 1, Highest: audit.SeverityCritical, Score: 71, Failed: true, Files: 3, Errors: 1}, data.Summary)
+	require.Equal(t, 1, data.Summary.exitCode())
+	require.Len(t, data.Files, 3)
+}
+
+func Test_builtinFinding(t *testing.T) {
+	cfg := &config.ServiceConfig{Endpoints: []*config.EndpointConfig{
+		{Endpoint: "/users/{id}", Method: "GET", ExtraConfig: config.ExtraConfig{"auth/basic": map[string]interface{}{}}},
+		{Endpoint: "/orders", Method: "POST", ExtraConfig: config.ExtraConfig{"qos/ratelimit/router": map[string]interface{}{}}},
+		{Endpoint: "/items", Method: "GET"},
+	}}
+
+	// every recommendation is a single finding, with the elements it probably refers to
+	require.Equal(t, policy.Finding{Rule: "1.1.1", Severity: audit.SeverityHigh, Message: "m", Candidates: []string{"GET /users/{id}"}},
+		builtinFinding(cfg, audit.Recommendation{Rule: "1.1.1", Severity: audit.SeverityHigh, Message: "m"}))
+	require.Equal(t, policy.Finding{Rule: "3.1.2", Severity: audit.SeverityLow, Message: "m", Candidates: []string{"GET /users/{id}", "GET /items"}},
+		builtinFinding(cfg, audit.Recommendation{Rule: "3.1.2", Severity: audit.SeverityLow, Message: "m"}))
+	require.Equal(t, policy.Finding{Rule: "2.2.1", Severity: audit.SeverityMedium, Message: "m"},
+		builtinFinding(cfg, audit.Recommendation{Rule: "2.2.1", Severity: audit.SeverityMedium, Message: "m"}))
+
+	inline, err := policy.InlineSuppressions(config.ServiceConfig{Endpoints: []*config.EndpointConfig{{
+		Endpoint:    "/users/{id}",
+		Method:      "GET",
+		ExtraConfig: config.ExtraConfig{policy.InlineSuppressionKey: []interface{}{map[string]interface{}{"rule": "3.1.2", "reason": "r"}}},
+	}}})
+	require.NoError(t, err)
+	require.NoError(t, checkScopedSuppressions(inline, nil))
+
+	inline, err = policy.InlineSuppressions(config.ServiceConfig{Endpoints: []*config.EndpointConfig{{
+		Endpoint:    "/users/{id}",
+		Method:      "GET",
+		ExtraConfig: config.ExtraConfig{policy.InlineSuppressionKey: []interface{}{map[string]interface{}{"rule": "2.2.1", "reason": "r"}}},
+	}}})
+	require.NoError(t, err)
+	require.EqualError(t, checkScopedSuppressions(inline, nil), "GET /users/{id}: the findings of the rule 2.2.1 are not located in an element, suppress them without selector in a suppressions file")
+	require.NoError(t, checkScopedSuppressions(inline, []policy.Rule{{ID: "2.2.1"}}))
+}
+
+func Test_recordAuditHistory_hidden(t *testing.T) {
+	dir := t.TempDir()
+	low := auditRecommendation{Recommendation: audit.Recommendation{Rule: "3.1.2", Severity: audit.SeverityLow, Message: "m"}}
+	high := auditRecommendation{Recommendation: audit.Recommendation{Rule: "1.1.1", Severity: audit.SeverityHigh, Message: "n"}}
+
+	first := auditReport{Recommendations: []auditRecommendation{low, high}, all: []auditRecommendation{low, high}}
+	require.NoError(t, recordAuditHistory(dir, "krakend.json", &first, time.Now().Add(-time.Hour)))
+
+	// the next run hides the low recommendations with --severity
+	second := auditReport{Recommendations: []auditRecommendation{high}, all: []auditRecommendation{low, high}}
+	require.NoError(t, recordAuditHistory(dir, "krakend.json", &second, time.Now()))
+	require.NotNil(t, second.Delta)
+	require.Empty(t, second.Delta.Fixed)
+	require.Len(t, second.Delta.Unchanged, 2)
+}
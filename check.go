@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,9 +9,11 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/krakend/krakend-cobra/v2/dumper"
+	"github.com/krakend/krakend-cobra/v2/source"
 	"github.com/santhosh-tekuri/jsonschema/v6"
 
 	"github.com/luraproject/lura/v2/config"
@@ -41,126 +44,251 @@ func NewCheckCmd(rawSchema string) Command {
 	return CheckCommand
 }
 
-func checkFunc(cmd *cobra.Command, _ []string) { // skipcq: GO-R1005
-	if cfgFile == "" {
+func checkFunc(cmd *cobra.Command, args []string) {
+	paths, err := configPaths(args)
+	if err != nil {
+		cmd.Println(errorMsg("ERROR finding the configuration files:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+	if len(paths) == 0 {
 		cmd.Println(errorMsg("Please, provide the path to the configuration file with --config or see all the options with --help"))
 		os.Exit(1) // skipcq: RVV-A0003
 		return
 	}
 
-	cmd.Printf("Parsing configuration file: %s\n", cfgFile)
+	if len(paths) == 1 {
+		if !checkFile(cmd, configParser(), paths[0]) {
+			os.Exit(1) // skipcq: RVV-A0003
+		}
+		return
+	}
 
-	v, err := parser.Parse(cfgFile)
-	if err != nil {
-		cmd.Println(errorMsg("ERROR parsing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+	// the output of every file is buffered so it is not mixed with the others
+	type checkResult struct {
+		ok     bool
+		output string
+	}
+	results := forEachConfig(paths, func(path string) checkResult {
+		var buf bytes.Buffer
+		c := &cobra.Command{}
+		c.SetOut(&buf)
+		c.SetErr(&buf)
+		ok := checkFile(c, configParser(), path)
+		return checkResult{ok: ok, output: buf.String()}
+	})
+
+	failed := 0
+	for _, r := range results {
+		cmd.Println(strings.TrimRight(r.output, "\n") + "\n")
+		if !r.ok {
+			failed++
+		}
+	}
+	summary := fmt.Sprintf("%d configuration files checked: %d OK, %d failed", len(paths), len(paths)-failed, failed)
+	if failed > 0 {
+		cmd.Println(errorMsg(summary))
 		os.Exit(1) // skipcq: RVV-A0003
 		return
 	}
+	cmd.Println(summary)
+}
+
+// checkFile checks the configuration file with the parser, reporting the problems
+// found to the command. It returns false when the file is not valid.
+func checkFile(cmd *cobra.Command, p config.Parser, path string) bool { // skipcq: GO-R1005
+	cmd.Printf("Parsing configuration file: %s\n", path)
+
+	v, err := p.Parse(path)
+	if err != nil {
+		cmd.Println(errorMsg("ERROR parsing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		return false
+	}
+
+	sm := newSourceMap(p, path)
 
 	if customErrs := CustomValidationFunc(v); len(customErrs) > 0 {
 		eb := strings.Builder{}
 		for _, err := range customErrs {
-			eb.WriteString(fmt.Sprintf("\t%s\n", err.Error()))
+			eb.WriteString(fmt.Sprintf("\t%s: %s\n", sm.configError(err), err.Error()))
 		}
 
 		cmd.Println(errorMsg("ERROR validating the configuration file:\n") + eb.String())
-		os.Exit(1) // skipcq: RVV-A0003
-		return
+		return false
 	}
 
 	shouldLint := lintCurrentSchema || lintNoNetwork || (lintCustomSchemaPath != "")
 
 	if shouldLint {
-		var data []byte
-		var err error
-		if ls, ok := parser.(LastSourcer); ok {
-			data, err = ls.LastSource()
-		} else {
-			data, err = os.ReadFile(cfgFile)
-		}
-
+		data, err := sourceOf(p, path)
 		if err != nil {
 			cmd.Println(errorMsg("ERROR loading the configuration content:") + fmt.Sprintf("\t%s\n", err.Error()))
-			os.Exit(1) // skipcq: RVV-A0003
-			return
+			return false
 		}
 
 		var raw interface{}
 		if err := json.Unmarshal(data, &raw); err != nil {
 			cmd.Println(errorMsg("ERROR converting configuration content to JSON:") + fmt.Sprintf("\t%s\n", err.Error()))
-			os.Exit(1) // skipcq: RVV-A0003
-			return
+			return false
 		}
 
-		var sch *jsonschema.Schema
-		var compilationErr error
-		if lintNoNetwork {
-			rawSchema, parseError := jsonschema.UnmarshalJSON(strings.NewReader(rawEmbedSchema))
-			if parseError != nil {
-				cmd.Println(errorMsg("ERROR parsing the embed schema:") + fmt.Sprintf("\t%s\n", parseError.Error()))
-				os.Exit(1) // skipcq: RVV-A0003
-				return
-			}
-
-			compiler := jsonschema.NewCompiler()
-			compiler.AddResource("schema.json", rawSchema)
+		sch, label, err := checkSchema()
+		if err != nil {
+			cmd.Println(errorMsg(label) + fmt.Sprintf("\t%s\n", err.Error()))
+			return false
+		}
 
-			sch, compilationErr = compiler.Compile("schema.json")
-		} else {
-			if lintCustomSchemaPath == "" {
-				lintCustomSchemaPath = fmt.Sprintf(SchemaURL, getVersionMinor(core.KrakendVersion))
+		if err = sch.Validate(raw); err != nil {
+			var verr *jsonschema.ValidationError
+			if !errors.As(err, &verr) {
+				cmd.Println(errorMsg("ERROR linting the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+				return false
 			}
-
-			httpLoader := SchemaHttpLoader(http.Client{
-				Timeout: 10 * time.Second,
-			})
-
-			loader := jsonschema.SchemeURLLoader{
-				"file":  jsonschema.FileLoader{},
-				"http":  &httpLoader,
-				"https": &httpLoader,
+			eb := strings.Builder{}
+			for _, err := range sm.schemaErrors(verr) {
+				eb.WriteString(fmt.Sprintf("\t%s\n", err.Error()))
 			}
-			compiler := jsonschema.NewCompiler()
-			compiler.UseLoader(loader)
-
-			sch, compilationErr = compiler.Compile(lintCustomSchemaPath)
+			cmd.Println(errorMsg("ERROR linting the configuration file:\n") + eb.String())
+			return false
 		}
+	}
 
-		if compilationErr != nil {
-			cmd.Println(errorMsg("ERROR compiling the schema:") + fmt.Sprintf("\t%s\n", compilationErr.Error()))
-			os.Exit(1) // skipcq: RVV-A0003
-			return
+	// --effective implies --debug, as the effective settings are part of the dump. The
+	// certificates are inspected on their own when the regular dump is not printed.
+	if checkDebug > 0 || checkEffective || checkInspectCerts {
+		filter, err := dumper.NewFilter(checkFilterPath, checkFilterMethod, checkFilterHost, checkFilterNamespace)
+		if err != nil {
+			cmd.Println(errorMsg("ERROR parsing the dump filters:") + fmt.Sprintf("\t%s\n", err.Error()))
+			return false
 		}
-
-		if err = sch.Validate(raw); err != nil {
-			cmd.Println(errorMsg("ERROR linting the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
-			os.Exit(1) // skipcq: RVV-A0003
-			return
+		cc := dumper.NewWithColors(cmd, checkDumpPrefix, max(checkDebug, 1), IsTTY).WithRedactor(checkRedactor()).WithFilter(filter).WithCertInspection(checkInspectCerts)
+		dump := cc.Dump
+		switch {
+		case checkEffective:
+			dump = func(v config.ServiceConfig) error {
+				data, err := sourceOf(p, path)
+				if err != nil {
+					return err
+				}
+				if err := cc.DumpEffective(v, data, source.FormatFromPath(path)); err != nil {
+					return err
+				}
+				if checkInspectCerts {
+					return cc.InspectCerts(v)
+				}
+				return nil
+			}
+		case checkDebug == 0:
+			dump = cc.InspectCerts
 		}
-	}
-
-	if checkDebug > 0 {
-		cc := dumper.NewWithColors(cmd, checkDumpPrefix, checkDebug, IsTTY)
-		if err := cc.Dump(v); err != nil {
+		if err := dump(v); err != nil {
 			cmd.Println(errorMsg("ERROR checking the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
-			os.Exit(1) // skipcq: RVV-A0003
-			return
+			return false
 		}
 	}
 
 	if checkGinRoutes {
-		if err := RunRouterFunc(v); err != nil {
-			cmd.Println(errorMsg("ERROR testing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
-			os.Exit(1) // skipcq: RVV-A0003
-			return
+		routerMu.Lock()
+		err := RunRouterFunc(v)
+		routerMu.Unlock()
+		if err != nil {
+			cmd.Println(errorMsg("ERROR testing the configuration file:") + fmt.Sprintf("\t%s: %s\n", path, err.Error()))
+			return false
 		}
 	}
 
 	if IsTTY {
 		cmd.Printf("%sSyntax OK!%s\n", dumper.ColorGreen, dumper.ColorReset)
-		return
+		return true
 	}
 	cmd.Println("Syntax OK!")
+	return true
+}
+
+// routerMu serializes the router tests, as all of them listen on the configured port
+var routerMu sync.Mutex
+
+var (
+	schemaMu    sync.Mutex
+	schemaKey   string
+	schema      *jsonschema.Schema
+	schemaLabel string
+	schemaErr   error
+)
+
+// checkSchema compiles the schema to lint the configuration files, returning the
+// label of the error to report when it fails. The schema is compiled once for all
+// the files checked with the same lint flags.
+func checkSchema() (*jsonschema.Schema, string, error) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	if !lintNoNetwork && lintCustomSchemaPath == "" {
+		lintCustomSchemaPath = fmt.Sprintf(SchemaURL, getVersionMinor(core.KrakendVersion))
+	}
+	key := fmt.Sprintf("%t|%s", lintNoNetwork, lintCustomSchemaPath)
+	if key != schemaKey {
+		schema, schemaLabel, schemaErr = compileSchema()
+		schemaKey = key
+	}
+	return schema, schemaLabel, schemaErr
+}
+
+func compileSchema() (*jsonschema.Schema, string, error) {
+	if lintNoNetwork {
+		return compileEmbedSchema()
+	}
+
+	httpLoader := SchemaHttpLoader(http.Client{
+		Timeout: 10 * time.Second,
+	})
+
+	loader := jsonschema.SchemeURLLoader{
+		"file":  jsonschema.FileLoader{},
+		"http":  &httpLoader,
+		"https": &httpLoader,
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(loader)
+
+	sch, err := compiler.Compile(lintCustomSchemaPath)
+	if err != nil {
+		return nil, "ERROR compiling the schema:", err
+	}
+	return sch, "", nil
+}
+
+// compileEmbedSchema compiles the schema embedded in the binary
+func compileEmbedSchema() (*jsonschema.Schema, string, error) {
+	rawSchema, err := jsonschema.UnmarshalJSON(strings.NewReader(rawEmbedSchema))
+	if err != nil {
+		return nil, "ERROR parsing the embed schema:", err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", rawSchema); err != nil {
+		return nil, "ERROR parsing the embed schema:", err
+	}
+	sch, err := compiler.Compile("schema.json")
+	if err != nil {
+		return nil, "ERROR compiling the schema:", err
+	}
+	return sch, "", nil
+}
+
+// lastSource returns the content of the configuration file as seen by the parser
+func lastSource() ([]byte, error) {
+	return sourceOf(parser, cfgFile)
+}
+
+// checkRedactor returns the redactor for the dumps, extending the default sensitive
+// keys with the ones passed with --redact-keys
+func checkRedactor() dumper.Redactor {
+	if checkNoRedact {
+		return dumper.NewRedactor()
+	}
+	keys := append([]string{}, dumper.SensitiveKeys...)
+	keys = append(keys, strings.Split(checkRedactKeys, ",")...)
+	return dumper.NewRedactor(keys...)
 }
 
 var CustomValidationFunc = func(_ config.ServiceConfig) []error {
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkFile_inspectCerts(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeExpiredCert(t, dir)
+	cfgPath := filepath.Join(dir, "krakend.json")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(fmt.Sprintf(
+		`{"version": 3, "tls": {"keys": [{"public_key": %q, "private_key": %q}]}}`, certPath, keyPath)), 0o600))
+
+	defer func(d int, e, i bool) { checkDebug, checkEffective, checkInspectCerts = d, e, i }(checkDebug, checkEffective, checkInspectCerts)
+
+	for name, tc := range map[string]struct {
+		debug     int
+		effective bool
+		dumped    string
+	}{
+		"without debug":  {},
+		"with debug":     {debug: 1, dumped: "Global settings"},
+		"with effective": {effective: true, dumped: "Effective settings of 0 API endpoint(s)"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			checkDebug, checkEffective, checkInspectCerts = tc.debug, tc.effective, true
+
+			var buf bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+
+			require.False(t, checkFile(cmd, config.NewParser(), cfgPath))
+			require.Contains(t, buf.String(), "Subject: CN=expired")
+			require.Contains(t, buf.String(), "certificate \"CN=expired\" expired at")
+			require.NotContains(t, buf.String(), "Syntax OK!")
+			if tc.dumped != "" {
+				require.Contains(t, buf.String(), tc.dumped)
+			} else {
+				require.NotContains(t, buf.String(), "Global settings")
+			}
+		})
+	}
+}
+
+func writeExpiredCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "expired"},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(-24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "expired.crt")
+	keyPath := filepath.Join(dir, "expired.key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certPath, keyPath
+}
@@ -46,6 +46,12 @@ func StringFlagBuilder(dst *string, long, short, defaultValue, help string) Flag
 	}
 }
 
+func StringArrayFlagBuilder(dst *[]string, long, short string, defaultValue []string, help string) FlagBuilder {
+	return func(cmd *cobra.Command) {
+		cmd.PersistentFlags().StringArrayVarP(dst, long, short, defaultValue, help)
+	}
+}
+
 func BoolFlagBuilder(dst *bool, long, short string, defaultValue bool, help string) FlagBuilder {
 	return func(cmd *cobra.Command) {
 		cmd.PersistentFlags().BoolVarP(dst, long, short, defaultValue, help)
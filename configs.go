@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/luraproject/lura/v2/config"
+)
+
+// ParserFactory returns a new parser for every configuration file processed by the
+// commands accepting several of them, so they are parsed concurrently. When it is nil,
+// the calls to the configured parser are serialized, and the rest of the processing of
+// every file runs concurrently.
+var ParserFactory func() config.Parser
+
+// configWorkers is the size of the pool processing several configuration files
+var configWorkers = runtime.NumCPU()
+
+// parserMu serializes the calls to the configured parser when there is no ParserFactory
+var parserMu sync.Mutex
+
+// configPaths returns the configuration files to process: the one in --config
+// followed by the arguments, with the globs expanded and without duplicates
+func configPaths(args []string) ([]string, error) {
+	patterns := args
+	if cfgFile != "" {
+		patterns = append([]string{cfgFile}, args...)
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			if _, err := os.Stat(pattern); err != nil {
+				return nil, fmt.Errorf("no configuration files match %s", pattern)
+			}
+			matches = []string{pattern}
+		}
+		for _, m := range matches {
+			if !slices.Contains(paths, m) {
+				paths = append(paths, m)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// configParser returns the parser for one of the configuration files
+func configParser() config.Parser {
+	if ParserFactory != nil {
+		return ParserFactory()
+	}
+	return &sharedParser{}
+}
+
+// sharedParser locks the configured parser only while parsing, keeping the source seen
+// by it, so the parser can be shared by the workers processing several files
+type sharedParser struct {
+	path   string
+	source []byte
+	err    error
+}
+
+func (s *sharedParser) Parse(path string) (config.ServiceConfig, error) {
+	parserMu.Lock()
+	defer parserMu.Unlock()
+
+	s.path = path
+	v, err := parser.Parse(path)
+	if ls, ok := parser.(LastSourcer); ok {
+		s.source, s.err = ls.LastSource()
+	}
+	return v, err
+}
+
+func (s *sharedParser) LastSource() ([]byte, error) {
+	if _, ok := parser.(LastSourcer); !ok {
+		return os.ReadFile(s.path)
+	}
+	return s.source, s.err
+}
+
+// forEachConfig calls f for every path with a pool of workers, and returns the results
+// in the order of the paths
+func forEachConfig[T any](paths []string, f func(path string) T) []T {
+	results := make([]T, len(paths))
+	jobs := make(chan int)
+
+	workers := min(configWorkers, len(paths))
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = f(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// sourceOf returns the content of the configuration file as seen by the parser
+func sourceOf(p config.Parser, path string) ([]byte, error) {
+	if ls, ok := p.(LastSourcer); ok {
+		return ls.LastSource()
+	}
+	return os.ReadFile(path)
+}
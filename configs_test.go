@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_configPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json", "c.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o600))
+	}
+	defer func(f string) { cfgFile = f }(cfgFile)
+
+	cfgFile = filepath.Join(dir, "c.yaml")
+	paths, err := configPaths([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "a.json")})
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "c.yaml"), filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")}, paths)
+
+	cfgFile = ""
+	paths, err = configPaths(nil)
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	_, err = configPaths([]string{filepath.Join(dir, "*.toml")})
+	require.ErrorContains(t, err, "no configuration files match")
+}
+
+func Test_forEachConfig(t *testing.T) {
+	defer func(w int) { configWorkers = w }(configWorkers)
+	configWorkers = 3
+
+	paths := []string{"a", "b", "c", "d", "e", "f", "g"}
+	require.Equal(t, []string{"A", "B", "C", "D", "E", "F", "G"}, forEachConfig(paths, strings.ToUpper))
+}
+
+type sourceParser struct {
+	last string
+}
+
+func (p *sourceParser) Parse(path string) (config.ServiceConfig, error) {
+	p.last = path
+	return config.ServiceConfig{Name: path}, nil
+}
+
+func (p *sourceParser) LastSource() ([]byte, error) {
+	return []byte("rendered " + p.last), nil
+}
+
+func Test_configParser_shared(t *testing.T) {
+	defer func(p config.Parser, f func() config.Parser) { parser, ParserFactory = p, f }(parser, ParserFactory)
+	ParserFactory = nil
+
+	parser = &sourceParser{}
+	a, b := configParser(), configParser()
+	v, err := a.Parse("a.json")
+	require.NoError(t, err)
+	require.Equal(t, "a.json", v.Name)
+	_, err = b.Parse("b.json")
+	require.NoError(t, err)
+
+	// the lock is only held while parsing and every file keeps its own source
+	require.True(t, parserMu.TryLock())
+	parserMu.Unlock()
+	data, err := sourceOf(a, "a.json")
+	require.NoError(t, err)
+	require.Equal(t, "rendered a.json", string(data))
+
+	path := filepath.Join(t.TempDir(), "c.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 3}`), 0o600))
+	parser = config.NewParser()
+	c := configParser()
+	_, err = c.Parse(path)
+	require.NoError(t, err)
+	data, err = sourceOf(c, path)
+	require.NoError(t, err)
+	require.Equal(t, `{"version": 3}`, string(data))
+}
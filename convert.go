@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/spf13/cobra"
+)
+
+func convertFunc(cmd *cobra.Command, _ []string) {
+	if cfgFile == "" {
+		cmd.Println(errorMsg("Please, provide the path to the configuration file with --config or see all the options with --help"))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	to := convertTo
+	if to == "" && convertOutput != "" {
+		to = source.FormatFromPath(convertOutput)
+	}
+	switch to {
+	case source.JSON, source.YAML, source.TOML:
+	default:
+		cmd.Println(errorMsg("Please, provide the output format with --to: json, yaml or toml"))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	data, err := lastSource()
+	if err != nil {
+		cmd.Println(errorMsg("ERROR loading the configuration content:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	// the content returned by a LastSourcer is already rendered as JSON
+	from := source.FormatFromPath(cfgFile)
+	if _, ok := parser.(LastSourcer); ok {
+		from = source.JSON
+	}
+
+	original, err := source.Decode(data, from)
+	if err != nil {
+		cmd.Println(errorMsg("ERROR decoding the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	converted, err := source.Encode(original, to, convertIndent)
+	if err != nil {
+		cmd.Println(errorMsg("ERROR converting the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	if !convertNoVerify {
+		if err := verifyConversion(original, converted, to); err != nil {
+			cmd.Println(errorMsg("ERROR verifying the converted configuration:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+	}
+
+	if convertOutput == "" {
+		if _, err := fmt.Fprint(cmd.OutOrStdout(), string(converted)); err != nil {
+			cmd.Println(errorMsg("ERROR writing the converted configuration:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+		}
+		return
+	}
+	if err := os.WriteFile(convertOutput, converted, 0o644); err != nil { // skipcq: GSC-G306
+		cmd.Println(errorMsg("ERROR writing the converted configuration:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+}
+
+// verifyConversion checks that the converted content parses to the same service
+// config than the original document
+func verifyConversion(original interface{}, converted []byte, format string) error {
+	decoded, err := source.Decode(converted, format)
+	if err != nil {
+		return err
+	}
+
+	want, err := source.Parse(original)
+	if err != nil {
+		return fmt.Errorf("parsing the original configuration: %w", err)
+	}
+	have, err := source.Parse(decoded)
+	if err != nil {
+		return fmt.Errorf("parsing the converted configuration: %w", err)
+	}
+
+	a, err := json.MarshalIndent(want, "", "  ")
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(have, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(a, b) {
+		return nil
+	}
+
+	diff, err := unifiedDiff("config", a, b)
+	if err != nil {
+		return err
+	}
+	return errors.New("the converted configuration is not equivalent:\n" + diff)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_convertFunc_stdout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "krakend.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 3, "name": "test"}`), 0o600))
+
+	defer func(f, to, out string) { cfgFile, convertTo, convertOutput = f, to, out }(cfgFile, convertTo, convertOutput)
+	cfgFile, convertTo, convertOutput = path, source.YAML, ""
+
+	stdout, stderr := captureStdout(t, convertFunc)
+	require.Equal(t, "version: 3\nname: test\n", stdout)
+	require.Empty(t, stderr)
+}
+
+// captureStdout runs the command without setting its output, so the writes to the
+// standard output and the ones to the standard error (like cobra's Print) are told apart
+func captureStdout(t *testing.T, run func(*cobra.Command, []string)) (string, string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer func(f *os.File) { os.Stdout = f }(os.Stdout)
+	os.Stdout = w
+
+	var stderr bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetErr(&stderr)
+
+	run(cmd, nil)
+	require.NoError(t, w.Close())
+	stdout, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(stdout), stderr.String()
+}
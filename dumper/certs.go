@@ -0,0 +1,232 @@
+package dumper
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+)
+
+// CertExpirationWarning is the time before the expiration of a certificate when the
+// inspection starts flagging it
+var CertExpirationWarning = 30 * 24 * time.Hour
+
+// CertInfo is the summary of a x509 certificate
+type CertInfo struct {
+	Subject   string
+	Issuer    string
+	SANs      []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	KeyType   string
+}
+
+// LoadCerts parses all the PEM encoded certificates in the file
+func LoadCerts(path string) ([]CertInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []CertInfo
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		res = append(res, certInfo(cert))
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%s: no PEM certificates found", path)
+	}
+	return res, nil
+}
+
+func certInfo(cert *x509.Certificate) CertInfo {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+	return CertInfo{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		SANs:      sans,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		KeyType:   keyType(cert.PublicKey),
+	}
+}
+
+func keyType(k interface{}) string {
+	switch pub := k.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d bits", pub.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + pub.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("%T", k)
+	}
+}
+
+// Validity returns an error if the certificate is not valid yet, expired or about
+// to expire at the given time
+func (ci CertInfo) Validity(now time.Time) error {
+	switch {
+	case now.Before(ci.NotBefore):
+		return fmt.Errorf("certificate %q is not valid until %s", ci.Subject, ci.NotBefore.Format(time.RFC3339))
+	case now.After(ci.NotAfter):
+		return fmt.Errorf("certificate %q expired at %s", ci.Subject, ci.NotAfter.Format(time.RFC3339))
+	case now.Add(CertExpirationWarning).After(ci.NotAfter):
+		return fmt.Errorf("certificate %q expires soon, at %s", ci.Subject, ci.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// WithCertInspection returns a copy of the dumper loading the certificates declared in
+// the TLS sections. The problems found are returned by Dump.
+func (c Dumper) WithCertInspection(enabled bool) Dumper {
+	c.inspectCerts = enabled
+	return c
+}
+
+// InspectCerts prints the details of the certificates declared in the TLS sections and
+// returns the problems found, without dumping the rest of the configuration
+func (c Dumper) InspectCerts(v config.ServiceConfig) error {
+	c.inspectCerts = true
+	c.cmd.Printf("%sTLS certificates%s\n", c.colorGreen, c.colorReset)
+
+	var errs []error
+	if v.TLS != nil {
+		errs = append(errs, c.dumpServerCerts(v.TLS)...)
+	}
+	if v.ClientTLS != nil {
+		errs = append(errs, c.dumpClientCerts(v.ClientTLS)...)
+	}
+	return certProblems(errs)
+}
+
+// certProblems joins the problems found when inspecting the certificates in a single error
+func certProblems(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Errorf("%d certificate problem(s) found:\n\t%s", len(errs), strings.Join(msgs, "\n\t"))
+}
+
+// serverKeys returns the key pairs used by the server, including the legacy
+// public_key and private_key fields as lura does
+func serverKeys(cfg *config.TLS) []config.TLSKeyPair {
+	keys := append([]config.TLSKeyPair{}, cfg.Keys...)
+	if cfg.PublicKey != "" || cfg.PrivateKey != "" {
+		keys = append(keys, config.TLSKeyPair{PublicKey: cfg.PublicKey, PrivateKey: cfg.PrivateKey})
+	}
+	return keys
+}
+
+// dumpServerCerts prints the key pairs and CA certificates of the server and returns
+// the problems found when inspecting them
+func (c Dumper) dumpServerCerts(cfg *config.TLS) []error {
+	var errs []error
+	prefix := c.checkDumpPrefix + c.checkDumpPrefix
+
+	keys := serverKeys(cfg)
+	c.cmd.Printf("%sTLS Keys: %d\n", c.checkDumpPrefix, len(keys))
+	for _, k := range keys {
+		c.cmd.Printf("%s- Public key: %s\n", prefix, k.PublicKey)
+		c.cmd.Printf("%s  Private key: %s\n", prefix, k.PrivateKey)
+		errs = append(errs, c.inspectKeyPair(prefix+"  ", k.PublicKey, k.PrivateKey)...)
+	}
+	c.cmd.Printf("%sTLS CA certs: %v\n", c.checkDumpPrefix, cfg.CaCerts)
+	return append(errs, c.inspectCAs(prefix, cfg.CaCerts)...)
+}
+
+// dumpClientCerts prints the client certificates and CA certificates used against the
+// backends and returns the problems found when inspecting them
+func (c Dumper) dumpClientCerts(cfg *config.ClientTLS) []error {
+	var errs []error
+	prefix := c.checkDumpPrefix + c.checkDumpPrefix
+
+	c.cmd.Printf("%sClient TLS certs: %d\n", c.checkDumpPrefix, len(cfg.ClientCerts))
+	for _, k := range cfg.ClientCerts {
+		c.cmd.Printf("%s- Certificate: %s\n", prefix, k.Certificate)
+		c.cmd.Printf("%s  Private key: %s\n", prefix, k.PrivateKey)
+		errs = append(errs, c.inspectKeyPair(prefix+"  ", k.Certificate, k.PrivateKey)...)
+	}
+	c.cmd.Printf("%sClient TLS CA certs: %v\n", c.checkDumpPrefix, cfg.CaCerts)
+	return append(errs, c.inspectCAs(prefix, cfg.CaCerts)...)
+}
+
+func (c Dumper) inspectKeyPair(prefix, cert, key string) []error {
+	if !c.inspectCerts {
+		return nil
+	}
+	loaded, errs := c.inspectCert(prefix, cert)
+	if !loaded {
+		return errs
+	}
+	if _, err := tls.LoadX509KeyPair(cert, key); err != nil {
+		err = fmt.Errorf("key pair %s and %s: %w", cert, key, err)
+		c.cmd.Printf("%s%s%s%s\n", prefix, c.colorRed, err.Error(), c.colorReset)
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (c Dumper) inspectCAs(prefix string, paths []string) []error {
+	if !c.inspectCerts {
+		return nil
+	}
+	var errs []error
+	for _, path := range paths {
+		c.cmd.Printf("%s- %s\n", prefix, path)
+		_, certErrs := c.inspectCert(prefix+"  ", path)
+		errs = append(errs, certErrs...)
+	}
+	return errs
+}
+
+// inspectCert prints the details of the certificates in the file. It reports if the
+// file was loaded and the problems found.
+func (c Dumper) inspectCert(prefix, path string) (bool, []error) {
+	certs, err := LoadCerts(path)
+	if err != nil {
+		c.cmd.Printf("%s%s%s%s\n", prefix, c.colorRed, err.Error(), c.colorReset)
+		return false, []error{err}
+	}
+
+	var errs []error
+	for _, ci := range certs {
+		c.cmd.Printf("%sSubject: %s\n", prefix, ci.Subject)
+		c.cmd.Printf("%sSANs: %s\n", prefix, strings.Join(ci.SANs, ", "))
+		c.cmd.Printf("%sIssuer: %s\n", prefix, ci.Issuer)
+		c.cmd.Printf("%sValid: %s - %s\n", prefix, ci.NotBefore.Format(time.RFC3339), ci.NotAfter.Format(time.RFC3339))
+		c.cmd.Printf("%sKey type: %s\n", prefix, ci.KeyType)
+		if err := ci.Validity(time.Now()); err != nil {
+			err = fmt.Errorf("%s: %w", path, err)
+			c.cmd.Printf("%s%s%s%s\n", prefix, c.colorRed, err.Error(), c.colorReset)
+			errs = append(errs, err)
+		}
+	}
+	return true, errs
+}
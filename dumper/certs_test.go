@@ -0,0 +1,133 @@
+package dumper
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumper_Dump_inspectCerts(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	validCert, validKey := writeCert(t, dir, "valid", now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	expiredCert, expiredKey := writeCert(t, dir, "expired", now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	soonCert, soonKey := writeCert(t, dir, "soon", now.Add(-time.Hour), now.Add(24*time.Hour))
+
+	tests := map[string]struct {
+		keys     []config.TLSKeyPair
+		caCerts  []string
+		inspect  bool
+		expected []string
+		err      string
+	}{
+		"without inspection": {
+			keys:     []config.TLSKeyPair{{PublicKey: expiredCert, PrivateKey: validKey}},
+			caCerts:  []string{"missing.pem"},
+			expected: []string{"TLS Keys: 1", "- Public key: " + expiredCert, "TLS CA certs: [missing.pem]"},
+		},
+		"valid": {
+			keys:     []config.TLSKeyPair{{PublicKey: validCert, PrivateKey: validKey}},
+			caCerts:  []string{validCert},
+			inspect:  true,
+			expected: []string{"Subject: CN=valid", "SANs: valid.example.com", "Key type: ECDSA P-256"},
+		},
+		"expired": {
+			keys:    []config.TLSKeyPair{{PublicKey: expiredCert, PrivateKey: expiredKey}},
+			inspect: true,
+			err:     "1 certificate problem(s) found:\n\t" + expiredCert + ": certificate \"CN=expired\" expired at",
+		},
+		"expires soon": {
+			caCerts: []string{soonCert},
+			inspect: true,
+			err:     "certificate \"CN=soon\" expires soon",
+		},
+		"key mismatch": {
+			keys:    []config.TLSKeyPair{{PublicKey: validCert, PrivateKey: soonKey}},
+			inspect: true,
+			err:     "private key does not match public key",
+		},
+		"missing file": {
+			keys:    []config.TLSKeyPair{{PublicKey: filepath.Join(dir, "missing.pem"), PrivateKey: validKey}},
+			inspect: true,
+			err:     "no such file or directory",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&buf)
+
+			cfg := config.ServiceConfig{TLS: &config.TLS{Keys: tc.keys, CaCerts: tc.caCerts}}
+			err := NewWithColors(cmd, "  ", 1, false).WithCertInspection(tc.inspect).Dump(cfg)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			for _, e := range tc.expected {
+				require.Contains(t, buf.String(), e)
+			}
+		})
+	}
+}
+
+func writeCert(t *testing.T, dir, name string, notBefore, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name + ".example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certPath, keyPath
+}
+
+func TestDumper_InspectCerts(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	validCert, validKey := writeCert(t, dir, "valid", now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	expiredCert, expiredKey := writeCert(t, dir, "expired", now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+
+	cfg := config.ServiceConfig{
+		Name:      "not dumped",
+		TLS:       &config.TLS{Keys: []config.TLSKeyPair{{PublicKey: validCert, PrivateKey: validKey}}},
+		ClientTLS: &config.ClientTLS{ClientCerts: []config.ClientTLSCert{{Certificate: expiredCert, PrivateKey: expiredKey}}},
+	}
+	err := NewWithColors(cmd, "  ", 1, false).InspectCerts(cfg)
+	require.ErrorContains(t, err, "1 certificate problem(s) found:\n\t"+expiredCert+": certificate \"CN=expired\" expired at")
+	require.Contains(t, buf.String(), "Subject: CN=valid")
+	require.Contains(t, buf.String(), "Client TLS certs: 1")
+	require.NotContains(t, buf.String(), "not dumped")
+}
@@ -1,8 +1,10 @@
 package dumper
 
 import (
+	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 
 	"github.com/luraproject/lura/v2/config"
 	"github.com/spf13/cobra"
@@ -17,6 +19,7 @@ func NewWithColors(cmd *cobra.Command, prefix string, verboseLevel int, enableCo
 		cmd:             cmd,
 		checkDumpPrefix: prefix,
 		verboseLevel:    verboseLevel,
+		redactor:        DefaultRedactor(),
 	}
 	if !enableColors {
 		return d
@@ -36,6 +39,9 @@ type Dumper struct {
 	cmd             *cobra.Command
 	checkDumpPrefix string
 	verboseLevel    int
+	redactor        Redactor
+	filter          Filter
+	inspectCerts    bool
 	colorRed        string
 	colorGreen      string
 	colorReset      string
@@ -46,6 +52,12 @@ type Dumper struct {
 	colorWhite      string
 }
 
+// WithRedactor returns a copy of the dumper masking the values with the received redactor
+func (c Dumper) WithRedactor(r Redactor) Dumper {
+	c.redactor = r
+	return c
+}
+
 func (c Dumper) Dump(v config.ServiceConfig) error {
 	c.cmd.Printf("%sGlobal settings%s\n", c.colorGreen, c.colorReset)
 	c.cmd.Printf("%sName: %s\n", c.checkDumpPrefix, v.Name)
@@ -88,11 +100,10 @@ func (c Dumper) Dump(v config.ServiceConfig) error {
 		c.cmd.Printf("%sUseH2C: %t\n", c.checkDumpPrefix, v.UseH2C)
 	}
 
+	var certErrs []error
 	if v.TLS != nil {
 		c.cmd.Printf("%sTLS Disabled: %t\n", c.checkDumpPrefix, v.TLS.IsDisabled)
-		// TODO: update this with the new field `Keys`:
-		c.cmd.Printf("%sTLS Public key: %s\n", c.checkDumpPrefix, v.TLS.PublicKey)
-		c.cmd.Printf("%sTLS Private key: %s\n", c.checkDumpPrefix, v.TLS.PrivateKey)
+		certErrs = append(certErrs, c.dumpServerCerts(v.TLS)...)
 		c.cmd.Printf("%sTLS Enable MTLS: %t\n", c.checkDumpPrefix, v.TLS.EnableMTLS)
 		c.cmd.Printf("%sTLS Disable System CA Pool: %v\n", c.checkDumpPrefix, v.TLS.DisableSystemCaPool)
 
@@ -112,6 +123,7 @@ func (c Dumper) Dump(v config.ServiceConfig) error {
 	if v.ClientTLS != nil {
 		c.cmd.Printf("%sClient TLS Allow Insecure Connections: %v\n", c.checkDumpPrefix, v.ClientTLS.AllowInsecureConnections)
 		c.cmd.Printf("%sClient TLS Disable System CA Pool: %v\n", c.checkDumpPrefix, v.ClientTLS.DisableSystemCaPool)
+		certErrs = append(certErrs, c.dumpClientCerts(v.ClientTLS)...)
 
 		if c.verboseLevel > 1 {
 			c.cmd.Printf("%sClient TLS Min version: %s\n", c.checkDumpPrefix, v.ClientTLS.MinVersion)
@@ -135,16 +147,48 @@ func (c Dumper) Dump(v config.ServiceConfig) error {
 		c.dumpExtraConfig(v.ExtraConfig, "")
 	}
 
-	c.cmd.Printf("%s%d API endpoint(s):%s\n", c.colorGreen, len(v.Endpoints), c.colorReset)
-	for _, endpoint := range v.Endpoints {
+	endpoints := c.endpoints(v.Endpoints)
+	c.cmd.Printf("%s%d API endpoint(s):%s\n", c.colorGreen, len(endpoints), c.colorReset)
+	for _, endpoint := range endpoints {
 		c.dumpEndpoint(endpoint)
 	}
 
-	c.cmd.Printf("%s%d async agent(s):%s\n", c.colorGreen, len(v.AsyncAgents), c.colorReset)
+	agents := make([]*config.AsyncAgent, 0, len(v.AsyncAgents))
 	for _, agent := range v.AsyncAgents {
+		if c.filter.Agent(agent) {
+			agents = append(agents, agent)
+		}
+	}
+	c.cmd.Printf("%s%d async agent(s):%s\n", c.colorGreen, len(agents), c.colorReset)
+	for _, agent := range agents {
 		c.dumpAgent(agent)
 	}
-	return nil
+
+	if !c.filter.IsEmpty() {
+		c.cmd.Printf("%s%d of %d endpoint(s) and %d of %d async agent(s) filtered out%s\n", c.colorYellow,
+			len(v.Endpoints)-len(endpoints), len(v.Endpoints), len(v.AsyncAgents)-len(agents), len(v.AsyncAgents), c.colorReset)
+	}
+
+	return certProblems(certErrs)
+}
+
+// WithFilter returns a copy of the dumper printing only the elements selected by the filter
+func (c Dumper) WithFilter(f Filter) Dumper {
+	c.filter = f
+	return c
+}
+
+func (c Dumper) endpoints(endpoints []*config.EndpointConfig) []*config.EndpointConfig {
+	if c.filter.IsEmpty() {
+		return endpoints
+	}
+	res := make([]*config.EndpointConfig, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if c.filter.Endpoint(endpoint) {
+			res = append(res, endpoint)
+		}
+	}
+	return res
 }
 
 func (c Dumper) dumpAgent(agent *config.AsyncAgent) {
@@ -168,8 +212,9 @@ func (c Dumper) dumpAgent(agent *config.AsyncAgent) {
 		c.dumpExtraConfig(agent.ExtraConfig, c.checkDumpPrefix)
 	}
 
-	c.cmd.Printf("%s%sConnecting to %d backend(s):%s\n", c.checkDumpPrefix, c.colorGreen, len(agent.Backend), c.colorReset)
-	for _, backend := range agent.Backend {
+	backends := c.filter.backends(agent.ExtraConfig, agent.Backend)
+	c.cmd.Printf("%s%sConnecting to %d backend(s):%s\n", c.checkDumpPrefix, c.colorGreen, len(backends), c.colorReset)
+	for _, backend := range backends {
 		c.dumpBackend(backend)
 	}
 }
@@ -197,8 +242,9 @@ func (c Dumper) dumpEndpoint(endpoint *config.EndpointConfig) {
 		c.dumpExtraConfig(endpoint.ExtraConfig, c.checkDumpPrefix)
 	}
 
-	c.cmd.Printf("%s%sConnecting to %d backend(s):%s\n", c.checkDumpPrefix, c.colorGreen, len(endpoint.Backend), c.colorReset)
-	for _, backend := range endpoint.Backend {
+	backends := c.filter.backends(endpoint.ExtraConfig, endpoint.Backend)
+	c.cmd.Printf("%s%sConnecting to %d backend(s):%s\n", c.checkDumpPrefix, c.colorGreen, len(backends), c.colorReset)
+	for _, backend := range backends {
 		c.dumpBackend(backend)
 	}
 }
@@ -241,18 +287,147 @@ func (c Dumper) dumpExtraConfig(cfg config.ExtraConfig, prefix string) {
 	for _, k := range keys {
 		c.cmd.Printf("%s%s- %s%s\n", prefix, c.colorYellow, k, c.colorReset)
 		if c.verboseLevel > 1 {
-			switch s := cfg[k].(type) {
-			case map[string]interface{}:
-				for i, v := range s {
-					c.cmd.Printf("\t%s%s: %+v\n", prefix, i, v)
+			v := c.redactor.Redact(cfg[k])
+			if lines, ok := render(k, v); ok {
+				for _, line := range lines {
+					c.cmd.Printf("%s%s%s\n", prefix, c.checkDumpPrefix, line)
 				}
-			case []interface{}:
-				c.cmd.Printf("\t%s: %+v\n", prefix, s)
-			default:
-				c.cmd.Printf("\t%s: %+v\n", prefix, s)
+				continue
+			}
+			c.dumpValue(v, prefix+c.checkDumpPrefix, 1)
+		}
+	}
+}
+
+// dumpValue renders the value as an indented tree with the keys sorted. The depth
+// of the tree and the length of the arrays and strings depend on the verbose level
+func (c Dumper) dumpValue(v interface{}, prefix string, depth int) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if summary, ok := c.nestedSummary(t[k], depth); ok {
+				c.cmd.Printf("%s%s: %s\n", prefix, k, summary)
+				continue
 			}
+			if isNested(t[k]) {
+				c.cmd.Printf("%s%s:\n", prefix, k)
+				c.dumpValue(t[k], prefix+c.checkDumpPrefix, depth+1)
+				continue
+			}
+			c.cmd.Printf("%s%s: %s\n", prefix, k, c.scalar(t[k]))
+		}
+	case []interface{}:
+		items := t
+		maxItems := c.maxItems()
+		if maxItems > 0 && len(items) > maxItems {
+			items = items[:maxItems]
+		}
+		for _, item := range items {
+			if summary, ok := c.nestedSummary(item, depth); ok {
+				c.cmd.Printf("%s- %s\n", prefix, summary)
+				continue
+			}
+			if isNested(item) {
+				c.cmd.Printf("%s-\n", prefix)
+				c.dumpValue(item, prefix+c.checkDumpPrefix, depth+1)
+				continue
+			}
+			c.cmd.Printf("%s- %s\n", prefix, c.scalar(item))
+		}
+		if len(items) < len(t) {
+			c.cmd.Printf("%s… %d more\n", prefix, len(t)-len(items))
 		}
+	default:
+		c.cmd.Printf("%s%s\n", prefix, c.scalar(t))
+	}
+}
+
+// nestedSummary returns a one line summary of the nested values exceeding the max depth
+// and the empty collections
+func (c Dumper) nestedSummary(v interface{}, depth int) (string, bool) {
+	maxDepth := c.maxDepth()
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if len(t) == 0 {
+			return "{}", true
+		}
+		if maxDepth > 0 && depth >= maxDepth {
+			return fmt.Sprintf("{… %d keys}", len(t)), true
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			return "[]", true
+		}
+		if maxDepth > 0 && depth >= maxDepth {
+			return fmt.Sprintf("[… %d items]", len(t)), true
+		}
+	}
+	return "", false
+}
+
+func isNested(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+func (c Dumper) scalar(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Sprintf("%v", v)
+	}
+	runes := []rune(s)
+	if maxLen := c.maxStringLength(); maxLen > 0 && len(runes) > maxLen {
+		return fmt.Sprintf("%s… %d more", string(runes[:maxLen]), len(runes)-maxLen)
+	}
+	return s
+}
+
+// maxDepth returns the levels of nested extra_config to render. Zero means no limit.
+func (c Dumper) maxDepth() int {
+	switch c.verboseLevel {
+	case 0, 1, 2:
+		return 2
+	case 3:
+		return 4
+	}
+	return 0
+}
+
+// maxItems returns the number of array items to render. Zero means no limit.
+func (c Dumper) maxItems() int {
+	switch c.verboseLevel {
+	case 0, 1, 2:
+		return 10
+	case 3:
+		return 50
+	}
+	return 0
+}
+
+// maxStringLength returns the number of characters of the strings to render.
+// Zero means no limit.
+func (c Dumper) maxStringLength() int {
+	switch c.verboseLevel {
+	case 0, 1, 2:
+		return 80
+	case 3:
+		return 200
 	}
+	return 0
 }
 
 func (c Dumper) methodColor(s string) string {
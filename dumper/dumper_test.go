@@ -0,0 +1,268 @@
+package dumper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumper_dumpExtraConfig(t *testing.T) {
+	cfg := config.ExtraConfig{
+		"qos/ratelimit/router": map[string]interface{}{
+			"max_rate":        100.0,
+			"client_max_rate": 1000000.0,
+			"strategy":        "ip",
+		},
+		"custom/nested": map[string]interface{}{
+			"a":     map[string]interface{}{"b": map[string]interface{}{"c": 1.0}},
+			"empty": []interface{}{},
+			"list":  []interface{}{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0, 8.0, 9.0, 10.0, 11.0, 12.0},
+			"long":  strings.Repeat("a", 90),
+			"objs":  []interface{}{map[string]interface{}{"x": true, "y": nil}},
+		},
+	}
+
+	tests := map[string]struct {
+		verboseLevel int
+		expected     string
+	}{
+		"names only": {
+			verboseLevel: 1,
+			expected: `- custom/nested
+- qos/ratelimit/router
+`,
+		},
+		"truncated": {
+			verboseLevel: 2,
+			expected: `- custom/nested
+  a:
+    b: {… 1 keys}
+  empty: []
+  list:
+    - 1
+    - 2
+    - 3
+    - 4
+    - 5
+    - 6
+    - 7
+    - 8
+    - 9
+    - 10
+    … 2 more
+  long: ` + strings.Repeat("a", 80) + `… 10 more
+  objs:
+    - {… 2 keys}
+- qos/ratelimit/router
+  client_max_rate: 1000000
+  max_rate: 100
+  strategy: ip
+`,
+		},
+		"full": {
+			verboseLevel: 4,
+			expected: `- custom/nested
+  a:
+    b:
+      c: 1
+  empty: []
+  list:
+    - 1
+    - 2
+    - 3
+    - 4
+    - 5
+    - 6
+    - 7
+    - 8
+    - 9
+    - 10
+    - 11
+    - 12
+  long: ` + strings.Repeat("a", 90) + `
+  objs:
+    -
+      x: true
+      y: null
+- qos/ratelimit/router
+  client_max_rate: 1000000
+  max_rate: 100
+  strategy: ip
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOutput(&buf)
+
+			NewWithColors(cmd, "  ", tc.verboseLevel, false).dumpExtraConfig(cfg, "")
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestDumper_dumpExtraConfig_renderer(t *testing.T) {
+	RegisterRenderer("qos/ratelimit/router", func(v interface{}) (string, error) {
+		cfg, ok := v.(map[string]interface{})
+		if !ok {
+			return "", errors.New("unexpected config")
+		}
+		return fmt.Sprintf("rate limit %v req/s, burst %v\nper client %v", cfg["max_rate"], cfg["capacity"], cfg["strategy"]), nil
+	})
+	defer func() {
+		renderersMu.Lock()
+		delete(renderers, "qos/ratelimit/router")
+		renderersMu.Unlock()
+	}()
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOutput(&buf)
+
+	NewWithColors(cmd, "  ", 2, false).dumpExtraConfig(config.ExtraConfig{
+		"qos/ratelimit/router": map[string]interface{}{"max_rate": 100.0, "capacity": 10.0, "strategy": "ip"},
+		"qos/other":            map[string]interface{}{"max_rate": 100.0},
+	}, "")
+	require.Equal(t, `- qos/other
+  max_rate: 100
+- qos/ratelimit/router
+  rate limit 100 req/s, burst 10
+  per client ip
+`, buf.String())
+
+	buf.Reset()
+	NewWithColors(cmd, "  ", 2, false).dumpExtraConfig(config.ExtraConfig{
+		"qos/ratelimit/router": []interface{}{"unexpected"},
+	}, "")
+	require.Equal(t, `- qos/ratelimit/router
+  - unexpected
+`, buf.String())
+}
+
+func TestDumper_DumpEffective(t *testing.T) {
+	raw := []byte(`{
+	"version": 3,
+	"timeout": "3s",
+	"host": ["http://global"],
+	"endpoints": [
+		{"endpoint": "/a", "cache_ttl": "10s", "output_encoding": "no-op", "backend": [{"url_pattern": "/a"}]},
+		{"endpoint": "/b", "method": "POST", "concurrent_calls": 2, "backend": [{"host": ["http://x"], "url_pattern": "/b", "method": "PUT"}]}
+	]
+}`)
+	cfg, err := config.NewParserWithFileReader(func(string) ([]byte, error) { return raw, nil }).Parse("krakend.json")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOutput(&buf)
+
+	require.NoError(t, NewWithColors(cmd, "  ", 1, false).DumpEffective(cfg, raw, source.JSON))
+	require.Equal(t, `Effective settings of 2 API endpoint(s):
+  - GET /a
+  Method: GET (lura default)
+  Timeout: 3s (inherited from the global config)
+  CacheTTL: 10s (explicit)
+  OutputEncoding: no-op (explicit)
+  Concurrent calls: 1 (lura default)
+    [+] GET /a
+    Hosts: [http://global] (inherited from the global config)
+    Method: GET (inherited from the endpoint)
+    Timeout: 3s (inherited from the endpoint)
+    Concurrent calls: 1 (inherited from the endpoint)
+    Encoding: no-op (inherited from the endpoint)
+    SD scheme: http (lura default)
+
+  - POST /b
+  Method: POST (explicit)
+  Timeout: 3s (inherited from the global config)
+  CacheTTL: 0s (lura default)
+  OutputEncoding: json (lura default)
+  Concurrent calls: 2 (explicit)
+    [+] PUT /b
+    Hosts: [http://x] (explicit)
+    Method: PUT (explicit)
+    Timeout: 3s (inherited from the endpoint)
+    Concurrent calls: 2 (inherited from the endpoint)
+    Encoding: json (lura default)
+    SD scheme: http (lura default)
+
+`, buf.String())
+
+	expected := buf.String()
+	buf.Reset()
+	require.NoError(t, NewWithColors(cmd, "  ", 1, false).DumpEffective(cfg, []byte(`version: 3
+timeout: 3s
+host: ["http://global"]
+endpoints:
+  - {endpoint: /a, cache_ttl: 10s, output_encoding: no-op, backend: [{url_pattern: /a}]}
+  - {endpoint: /b, method: POST, concurrent_calls: 2, backend: [{host: ["http://x"], url_pattern: /b, method: PUT}]}
+`), source.YAML))
+	require.Equal(t, expected, buf.String())
+
+	require.EqualError(t, NewWithColors(cmd, "  ", 1, false).DumpEffective(cfg, []byte(`{"endpoints": []}`), source.JSON),
+		"the configuration source has 0 endpoints but 2 were parsed")
+}
+
+func TestFilter(t *testing.T) {
+	users := &config.Backend{URLPattern: "/users", Host: []string{"http://users.internal"}}
+	profile := &config.Backend{URLPattern: "/profile", Host: []string{"http://profile"}, ExtraConfig: config.ExtraConfig{"qos/ratelimit/proxy": nil}}
+	me := &config.EndpointConfig{Endpoint: "/me", Method: "GET", ExtraConfig: config.ExtraConfig{"auth/validator": nil}, Backend: []*config.Backend{users, profile}}
+	update := &config.EndpointConfig{Endpoint: "/users/:id", Method: "PUT", Backend: []*config.Backend{users}}
+	agent := &config.AsyncAgent{Name: "users-agent", Backend: []*config.Backend{users}}
+
+	tests := map[string]struct {
+		path, method, host, namespace string
+		endpoints                     []*config.EndpointConfig
+		agent                         bool
+		backends                      []*config.Backend
+	}{
+		"empty":            {endpoints: []*config.EndpointConfig{me, update}, agent: true, backends: []*config.Backend{users, profile}},
+		"glob path":        {path: "/users/*", endpoints: []*config.EndpointConfig{update}, backends: []*config.Backend{users, profile}},
+		"regexp path":      {path: "re:^/(me|users)", endpoints: []*config.EndpointConfig{me, update}, backends: []*config.Backend{users, profile}},
+		"method":           {method: "get,post", endpoints: []*config.EndpointConfig{me}, backends: []*config.Backend{users, profile}},
+		"host":             {host: "*profile", endpoints: []*config.EndpointConfig{me}, backends: []*config.Backend{profile}},
+		"endpoint ns":      {namespace: "auth/validator", endpoints: []*config.EndpointConfig{me}, backends: []*config.Backend{users, profile}},
+		"backend ns":       {namespace: "qos/ratelimit/proxy", endpoints: []*config.EndpointConfig{me}, backends: []*config.Backend{profile}},
+		"unknown ns":       {namespace: "unknown"},
+		"host and ns":      {host: "*.internal", namespace: "auth/validator", endpoints: []*config.EndpointConfig{me}, backends: []*config.Backend{users}},
+		"agent by name":    {path: "users-*", agent: true, backends: []*config.Backend{users, profile}},
+		"agent by backend": {host: "*.internal", endpoints: []*config.EndpointConfig{me, update}, agent: true, backends: []*config.Backend{users}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewFilter(tc.path, tc.method, tc.host, tc.namespace)
+			require.NoError(t, err)
+
+			var endpoints []*config.EndpointConfig
+			for _, e := range []*config.EndpointConfig{me, update} {
+				if f.Endpoint(e) {
+					endpoints = append(endpoints, e)
+				}
+			}
+			require.Equal(t, tc.endpoints, endpoints)
+			require.Equal(t, tc.agent, f.Agent(agent))
+
+			var backends []*config.Backend
+			for _, b := range me.Backend {
+				if f.Backend(me.ExtraConfig, b) {
+					backends = append(backends, b)
+				}
+			}
+			require.Equal(t, tc.backends, backends)
+		})
+	}
+
+	_, err := NewFilter("re:(", "", "", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package dumper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/luraproject/lura/v2/encoding"
+)
+
+// Origins of the effective values of the endpoints and backends
+const (
+	OriginExplicit = "explicit"
+	OriginEndpoint = "inherited from the endpoint"
+	OriginGlobal   = "inherited from the global config"
+	OriginDefault  = "lura default"
+)
+
+// rawService keeps the fields of the source config that lura propagates to the
+// endpoints and backends, so the dump can tell where each effective value comes from
+type rawService struct {
+	Timeout        json.RawMessage `json:"timeout"`
+	CacheTTL       json.RawMessage `json:"cache_ttl"`
+	Host           []string        `json:"host"`
+	OutputEncoding json.RawMessage `json:"output_encoding"`
+	Endpoints      []rawEndpoint   `json:"endpoints"`
+}
+
+type rawEndpoint struct {
+	Method          json.RawMessage `json:"method"`
+	Timeout         json.RawMessage `json:"timeout"`
+	CacheTTL        json.RawMessage `json:"cache_ttl"`
+	OutputEncoding  json.RawMessage `json:"output_encoding"`
+	ConcurrentCalls json.RawMessage `json:"concurrent_calls"`
+	Backend         []rawBackend    `json:"backend"`
+}
+
+type rawBackend struct {
+	Host     []string        `json:"host"`
+	Method   json.RawMessage `json:"method"`
+	Encoding json.RawMessage `json:"encoding"`
+	SDScheme json.RawMessage `json:"sd_scheme"`
+}
+
+func isSet(v json.RawMessage) bool {
+	return len(v) > 0 && string(v) != "null"
+}
+
+// origin returns the origin of a field defined at the endpoint and the global levels
+func origin(explicit, global json.RawMessage) string {
+	if isSet(explicit) {
+		return OriginExplicit
+	}
+	if isSet(global) {
+		return OriginGlobal
+	}
+	return OriginDefault
+}
+
+// DumpEffective dumps the settings applied to every endpoint and backend of the
+// normalized config, with the origin of each value. The raw param is the source of the
+// config in the given format (JSON, YAML or TOML), used to detect the explicit values.
+func (c Dumper) DumpEffective(v config.ServiceConfig, raw []byte, format string) error {
+	decoded, err := source.Decode(raw, format)
+	if err != nil {
+		return fmt.Errorf("decoding the configuration source: %w", err)
+	}
+	b, err := source.Encode(decoded, source.JSON, "")
+	if err != nil {
+		return fmt.Errorf("decoding the configuration source: %w", err)
+	}
+	var src rawService
+	if err := json.Unmarshal(b, &src); err != nil {
+		return fmt.Errorf("decoding the configuration source: %w", err)
+	}
+	if len(src.Endpoints) != len(v.Endpoints) {
+		return fmt.Errorf("the configuration source has %d endpoints but %d were parsed", len(src.Endpoints), len(v.Endpoints))
+	}
+
+	endpoints := c.endpoints(v.Endpoints)
+	c.cmd.Printf("%sEffective settings of %d API endpoint(s):%s\n", c.colorGreen, len(endpoints), c.colorReset)
+	for i, endpoint := range v.Endpoints {
+		if !c.filter.IsEmpty() && !c.filter.Endpoint(endpoint) {
+			continue
+		}
+		e := src.Endpoints[i]
+		c.cmd.Printf("%s- %s%s%s %s%s\n", c.checkDumpPrefix, c.methodColor(endpoint.Method), endpoint.Method, c.colorCyan, endpoint.Endpoint, c.colorReset)
+		c.dumpEffectiveValue(c.checkDumpPrefix, "Method", endpoint.Method, origin(e.Method, nil))
+		c.dumpEffectiveValue(c.checkDumpPrefix, "Timeout", endpoint.Timeout.String(), origin(e.Timeout, src.Timeout))
+		c.dumpEffectiveValue(c.checkDumpPrefix, "CacheTTL", endpoint.CacheTTL.String(), origin(e.CacheTTL, src.CacheTTL))
+		c.dumpEffectiveValue(c.checkDumpPrefix, "OutputEncoding", endpoint.OutputEncoding, origin(e.OutputEncoding, src.OutputEncoding))
+		c.dumpEffectiveValue(c.checkDumpPrefix, "Concurrent calls", fmt.Sprintf("%d", endpoint.ConcurrentCalls), origin(e.ConcurrentCalls, nil))
+
+		if len(e.Backend) != len(endpoint.Backend) {
+			return fmt.Errorf("the endpoint %s has %d backends in the source but %d were parsed", endpoint.Endpoint, len(e.Backend), len(endpoint.Backend))
+		}
+
+		prefix := c.checkDumpPrefix + c.checkDumpPrefix
+		for j, backend := range endpoint.Backend {
+			if !c.filter.IsEmpty() && !c.filter.Backend(endpoint.ExtraConfig, backend) {
+				continue
+			}
+			b := e.Backend[j]
+			c.cmd.Printf("%s[+] %s%s%s %s%s\n", prefix, c.methodColor(backend.Method), backend.Method, c.colorCyan, backend.URLPattern, c.colorReset)
+
+			hostOrigin := OriginDefault
+			if len(b.Host) > 0 {
+				hostOrigin = OriginExplicit
+			} else if len(src.Host) > 0 {
+				hostOrigin = OriginGlobal
+			}
+			c.dumpEffectiveValue(prefix, "Hosts", fmt.Sprintf("%v", backend.Host), hostOrigin)
+
+			methodOrigin := OriginEndpoint
+			if isSet(b.Method) {
+				methodOrigin = OriginExplicit
+			}
+			c.dumpEffectiveValue(prefix, "Method", backend.Method, methodOrigin)
+			c.dumpEffectiveValue(prefix, "Timeout", backend.Timeout.String(), OriginEndpoint)
+			c.dumpEffectiveValue(prefix, "Concurrent calls", fmt.Sprintf("%d", backend.ConcurrentCalls), OriginEndpoint)
+
+			encodingValue, encodingOrigin := backend.Encoding, OriginDefault
+			switch {
+			case endpoint.OutputEncoding == encoding.NOOP:
+				encodingOrigin = OriginEndpoint
+			case isSet(b.Encoding):
+				encodingOrigin = OriginExplicit
+			}
+			if encodingValue == "" {
+				encodingValue = encoding.JSON
+			}
+			c.dumpEffectiveValue(prefix, "Encoding", encodingValue, encodingOrigin)
+			c.dumpEffectiveValue(prefix, "SD scheme", backend.SDScheme, origin(b.SDScheme, nil))
+		}
+		c.cmd.Println("")
+	}
+
+	if !c.filter.IsEmpty() {
+		c.cmd.Printf("%s%d of %d endpoint(s) filtered out%s\n", c.colorYellow, len(v.Endpoints)-len(endpoints), len(v.Endpoints), c.colorReset)
+	}
+	return nil
+}
+
+func (c Dumper) dumpEffectiveValue(prefix, name, value, origin string) {
+	color := c.colorBlue
+	if origin == OriginExplicit {
+		color = c.colorReset
+	}
+	c.cmd.Printf("%s%s: %s %s(%s)%s\n", prefix, name, value, color, origin, c.colorReset)
+}
@@ -0,0 +1,154 @@
+package dumper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/luraproject/lura/v2/config"
+)
+
+// regexpPrefix marks the filter patterns to be used as regular expressions instead of globs
+const regexpPrefix = "re:"
+
+// Filter selects the endpoints, async agents and backends to dump. The zero value
+// selects everything.
+type Filter struct {
+	path      *regexp.Regexp
+	methods   []string
+	host      *regexp.Regexp
+	namespace string
+}
+
+// NewFilter returns a filter for the received criteria. Empty criteria are ignored.
+//
+// The path (matched against the endpoint path or the agent name) and the host
+// patterns are globs where * matches any sequence of characters, or regular
+// expressions when prefixed with "re:". The methods are a comma-separated list and
+// the namespace is an extra_config key the endpoint, agent or backend must define.
+func NewFilter(path, methods, host, namespace string) (Filter, error) {
+	f := Filter{namespace: namespace}
+
+	var err error
+	if f.path, err = compilePattern(path); err != nil {
+		return Filter{}, fmt.Errorf("invalid path filter: %w", err)
+	}
+	if f.host, err = compilePattern(host); err != nil {
+		return Filter{}, fmt.Errorf("invalid host filter: %w", err)
+	}
+	for _, m := range strings.Split(methods, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			f.methods = append(f.methods, strings.ToUpper(m))
+		}
+	}
+	return f, nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if re, ok := strings.CutPrefix(pattern, regexpPrefix); ok {
+		return regexp.Compile(re)
+	}
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// IsEmpty reports if the filter selects everything
+func (f Filter) IsEmpty() bool {
+	return f.path == nil && len(f.methods) == 0 && f.host == nil && f.namespace == ""
+}
+
+// Endpoint reports if the endpoint is selected by the filter
+func (f Filter) Endpoint(e *config.EndpointConfig) bool {
+	if f.path != nil && !f.path.MatchString(e.Endpoint) {
+		return false
+	}
+	if !f.method(e.Method) {
+		return false
+	}
+	return f.anyBackend(e.ExtraConfig, e.Backend)
+}
+
+// Agent reports if the async agent is selected by the filter. The agents are
+// filtered out when filtering by method.
+func (f Filter) Agent(a *config.AsyncAgent) bool {
+	if f.path != nil && !f.path.MatchString(a.Name) {
+		return false
+	}
+	if len(f.methods) > 0 {
+		return false
+	}
+	return f.anyBackend(a.ExtraConfig, a.Backend)
+}
+
+// Backend reports if the backend of a selected endpoint or agent must be dumped.
+// The parent extra_config is used for checking the namespace criteria.
+func (f Filter) Backend(parent config.ExtraConfig, b *config.Backend) bool {
+	if f.host != nil {
+		matched := false
+		for _, h := range b.Host {
+			if f.host.MatchString(h) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.namespace == "" {
+		return true
+	}
+	if _, ok := parent[f.namespace]; ok {
+		return true
+	}
+	_, ok := b.ExtraConfig[f.namespace]
+	return ok
+}
+
+func (f Filter) method(m string) bool {
+	if len(f.methods) == 0 {
+		return true
+	}
+	for _, method := range f.methods {
+		if strings.EqualFold(method, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) anyBackend(parent config.ExtraConfig, backends []*config.Backend) bool {
+	if f.host == nil && f.namespace == "" {
+		return true
+	}
+	if f.host == nil {
+		if _, ok := parent[f.namespace]; ok {
+			return true
+		}
+	}
+	for _, b := range backends {
+		if f.Backend(parent, b) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) backends(parent config.ExtraConfig, backends []*config.Backend) []*config.Backend {
+	if f.IsEmpty() {
+		return backends
+	}
+	res := make([]*config.Backend, 0, len(backends))
+	for _, b := range backends {
+		if f.Backend(parent, b) {
+			res = append(res, b)
+		}
+	}
+	return res
+}
@@ -0,0 +1,111 @@
+package dumper
+
+import (
+	"strings"
+)
+
+// RedactedValue replaces the values of the sensitive keys
+const RedactedValue = "*****"
+
+// SensitiveKeys are the patterns of the extra_config keys holding secrets. A key is
+// considered sensitive when it contains any of them, ignoring the case and treating
+// dashes and spaces as underscores (X-Api-Key matches api_key). The patterns starting
+// with = only match the keys equal to the rest of the pattern, for the generic names
+// like the users of the basic auth. Embedders can extend the list before creating the
+// dumpers.
+var SensitiveKeys = []string{
+	"secret",
+	"password",
+	"passwd",
+	"token",
+	"api_key",
+	"apikey",
+	"private_key",
+	"access_key",
+	"signing_key",
+	"credential",
+	"authorization",
+	"=cookie",
+	"=users",
+}
+
+// Redactor masks the values of the sensitive keys found in the extra_config
+type Redactor struct {
+	patterns []string
+}
+
+// NewRedactor returns a redactor for the received key patterns. A redactor without
+// patterns does not mask anything.
+func NewRedactor(patterns ...string) Redactor {
+	r := Redactor{patterns: make([]string, 0, len(patterns))}
+	for _, p := range patterns {
+		if p = normalizeKey(strings.TrimSpace(p)); p != "" && p != "=" {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+	return r
+}
+
+// DefaultRedactor returns a redactor for the SensitiveKeys
+func DefaultRedactor() Redactor {
+	return NewRedactor(SensitiveKeys...)
+}
+
+// IsSensitive reports if the values of the key must be masked
+func (r Redactor) IsSensitive(key string) bool {
+	key = normalizeKey(key)
+	for _, p := range r.patterns {
+		if exact, ok := strings.CutPrefix(p, "="); ok {
+			if key == exact {
+				return true
+			}
+			continue
+		}
+		if strings.Contains(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+var keySeparators = strings.NewReplacer("-", "_", " ", "_")
+
+func normalizeKey(key string) string {
+	return keySeparators.Replace(strings.ToLower(key))
+}
+
+// Redact returns a copy of the value with the sensitive entries masked. Besides the
+// sensitive keys, it also masks the value of the name/value pairs with a sensitive
+// name, like the headers set by the martian modifiers.
+func (r Redactor) Redact(v interface{}) interface{} {
+	if len(r.patterns) == 0 {
+		return v
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(t))
+		maskValue := false
+		for _, nameKey := range []string{"name", "header"} {
+			if name, ok := t[nameKey].(string); ok && r.IsSensitive(name) {
+				maskValue = true
+			}
+		}
+		for k, val := range t {
+			if r.IsSensitive(k) || (maskValue && (k == "value" || k == "values")) {
+				res[k] = RedactedValue
+				continue
+			}
+			res[k] = r.Redact(val)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(t))
+		for i, val := range t {
+			res[i] = r.Redact(val)
+		}
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,65 @@
+package dumper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	cfg := map[string]interface{}{
+		"client_id":     "abc",
+		"client_secret": "s3cr3t",
+		"nested": map[string]interface{}{
+			"Password": "pw",
+			"list":     []interface{}{map[string]interface{}{"api_key": "k"}, 1},
+		},
+		"header.Modifier": map[string]interface{}{
+			"scope": []interface{}{"request"},
+			"name":  "X-Api-Key",
+			"value": "abcdef",
+		},
+		"header.Other": map[string]interface{}{
+			"name":  "X-Custom",
+			"value": "visible",
+		},
+	}
+
+	require.Equal(t, map[string]interface{}{
+		"client_id":     "abc",
+		"client_secret": RedactedValue,
+		"nested": map[string]interface{}{
+			"Password": RedactedValue,
+			"list":     []interface{}{map[string]interface{}{"api_key": RedactedValue}, 1},
+		},
+		"header.Modifier": map[string]interface{}{
+			"scope": []interface{}{"request"},
+			"name":  "X-Api-Key",
+			"value": RedactedValue,
+		},
+		"header.Other": map[string]interface{}{
+			"name":  "X-Custom",
+			"value": "visible",
+		},
+	}, DefaultRedactor().Redact(cfg))
+
+	for key, sensitive := range map[string]bool{
+		"roles_key":        false,
+		"cache_key":        false,
+		"propagate_claims": false,
+		"cookie_key":       false,
+		"users_endpoint":   false,
+		"users":            true,
+		"Cookie":           true,
+		"private_key":      true,
+		"X-Auth-Token":     true,
+		"jwk_secret":       true,
+	} {
+		require.Equal(t, sensitive, DefaultRedactor().IsSensitive(key), key)
+	}
+
+	require.Equal(t, "abc", NewRedactor("secret").Redact(map[string]interface{}{"client_id": "abc"}).(map[string]interface{})["client_id"])
+	require.Equal(t, RedactedValue, NewRedactor("client_id").Redact(map[string]interface{}{"client_id": "abc"}).(map[string]interface{})["client_id"])
+	require.Equal(t, cfg, NewRedactor().Redact(cfg))
+	require.Equal(t, "s3cr3t", cfg["client_secret"])
+}
@@ -0,0 +1,45 @@
+package dumper
+
+import (
+	"strings"
+	"sync"
+)
+
+// Renderer turns the raw extra_config entry of a component into a human readable
+// summary. Multi-line summaries are indented line by line.
+type Renderer func(cfg interface{}) (string, error)
+
+var (
+	renderers   = map[string]Renderer{}
+	renderersMu sync.RWMutex
+)
+
+// RegisterRenderer sets the renderer for the extra_config entries with the given
+// namespace, replacing any previous one. Components should register their renderers
+// before the dump, usually in an init function.
+func RegisterRenderer(namespace string, r Renderer) {
+	renderersMu.Lock()
+	renderers[namespace] = r
+	renderersMu.Unlock()
+}
+
+func getRenderer(namespace string) (Renderer, bool) {
+	renderersMu.RLock()
+	r, ok := renderers[namespace]
+	renderersMu.RUnlock()
+	return r, ok
+}
+
+// render returns the lines of the summary of the namespace or false if there is no
+// renderer registered for the namespace or it fails
+func render(namespace string, cfg interface{}) ([]string, bool) {
+	r, ok := getRenderer(namespace)
+	if !ok {
+		return nil, false
+	}
+	summary, err := r(cfg)
+	if err != nil || summary == "" {
+		return nil, false
+	}
+	return strings.Split(strings.TrimRight(summary, "\n"), "\n"), true
+}
@@ -12,6 +12,9 @@ import (
 )
 
 func main() {
+	// the koanf parser accumulates the files it parses, so every file needs its own
+	// parser when auditing or checking several of them
+	cmd.ParserFactory = func() config.Parser { return koanf.New() }
 	cmd.Execute(koanf.New(), func(serviceConfig config.ServiceConfig) {
 		logger, _ := logging.NewLogger("DEBUG", os.Stdout, "")
 		gin.DefaultFactory(proxy.DefaultFactory(logger), logger).New().Run(serviceConfig)
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/krakend/krakend-cobra/v2/explain"
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/report"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/spf13/cobra"
+)
+
+func auditExplainFunc(cmd *cobra.Command, args []string) {
+	var rules []policy.Rule
+	if auditRulesPath != "" {
+		var err error
+		rules, err = policy.Load(auditRulesPath)
+		if err != nil {
+			cmd.PrintErrln(errorMsg("ERROR loading the audit rules:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+	}
+
+	var cfg *config.ServiceConfig
+	if cfgFile != "" {
+		v, err := configParser().Parse(cfgFile)
+		if err != nil {
+			cmd.PrintErrln(errorMsg("ERROR parsing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+		v.Normalize()
+		cfg = &v
+	}
+
+	tmpl, err := report.New(explanationFormatTmpl + `{{ range . }}{{ template "explanation" . }}{{ end }}`)
+	if err != nil {
+		cmd.PrintErrln(errorMsg("ERROR parsing the template:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	for i, id := range args {
+		e, ok := explain.Lookup(id, rules)
+		if !ok {
+			cmd.PrintErrln(errorMsg(fmt.Sprintf("ERROR unknown rule %s", id)))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+
+		// the custom rules report the elements violating them
+		var locations []string
+		if cfg != nil {
+			for _, r := range rules {
+				if r.ID != id {
+					continue
+				}
+				for _, f := range policy.Evaluate(*cfg, []policy.Rule{r}) {
+					locations = append(locations, f.Location)
+				}
+			}
+		}
+
+		rendered, err := e.Render(cfg, locations)
+		if err != nil {
+			cmd.PrintErrln(errorMsg("ERROR explaining the rule:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+		if i > 0 {
+			cmd.Println()
+		}
+		if err := tmpl.Execute(cmd.OutOrStdout(), []explain.Rendered{rendered}); err != nil {
+			cmd.PrintErrln(errorMsg("ERROR rendering the explanation:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package explain
+
+import "github.com/krakend/krakend-cobra/v2/policy"
+
+// Catalog holds the explanations of the built-in audit rules. Embedders can extend it
+// with the rules of their own audits.
+var Catalog = map[string]Explanation{
+	"1.1.1": {
+		Title: "Implement more secure alternatives than Basic Auth",
+		Rationale: "Basic authentication sends reusable credentials with every request and has no expiration nor scopes. " +
+			"Signed tokens validated by the gateway limit the exposure of the credentials and allow fine-grained authorization.",
+		Links:     []string{"https://www.krakend.io/docs/authorization/jwt-validation/"},
+		Scope:     policy.ScopeEndpoint,
+		Namespace: "auth/validator",
+		Replaces:  "auth/basic",
+		Remediation: map[string]interface{}{
+			"alg":     "RS256",
+			"jwk_url": "https://your-identity-provider/.well-known/jwks.json",
+			"cache":   true,
+		},
+	},
+	"1.2.1": {
+		Title: "Prioritize using JWT for endpoint authorization",
+		Rationale: "Endpoints without authorization are open to anyone reaching the gateway. " +
+			"Validating a signed token at the gateway keeps the anonymous traffic away from the backends.",
+		Links:     []string{"https://www.krakend.io/docs/authorization/jwt-validation/"},
+		Scope:     policy.ScopeEndpoint,
+		Namespace: "auth/validator",
+		Remediation: map[string]interface{}{
+			"alg":     "RS256",
+			"jwk_url": "https://your-identity-provider/.well-known/jwks.json",
+			"cache":   true,
+		},
+	},
+	"2.1.2": {
+		Title: "Enable TLS or use a terminator in front of KrakenD",
+		Rationale: "Without TLS the credentials and the content of the requests travel in clear text. " +
+			"Set the tls section of the service, unless a load balancer terminates the TLS connections before the gateway.",
+		Links: []string{"https://www.krakend.io/docs/service-settings/tls/"},
+		Scope: policy.ScopeService,
+	},
+	"2.1.7": {
+		Title:     "Enable HTTP security headers",
+		Rationale: "The security headers tell the browsers to use HTTPS and block clickjacking, MIME sniffing and XSS attacks.",
+		Links:     []string{"https://www.krakend.io/docs/service-settings/security/"},
+		Scope:     policy.ScopeService,
+		Namespace: "security/http",
+		Remediation: map[string]interface{}{
+			"frame_deny":           true,
+			"content_type_nosniff": true,
+			"browser_xss_filter":   true,
+			"sts_seconds":          31536000,
+		},
+	},
+	"2.2.1": {
+		Title:     "Hide the version banner in runtime",
+		Rationale: "The version header tells attackers which release of the gateway is running, and which vulnerabilities to try.",
+		Links:     []string{"https://www.krakend.io/docs/service-settings/router-options/"},
+		Scope:     policy.ScopeService,
+		Namespace: "router",
+		Remediation: map[string]interface{}{
+			"hide_version_header": true,
+		},
+	},
+	"2.2.2": {
+		Title:     "Enable CORS",
+		Rationale: "Without a CORS policy, browsers cannot tell which origins are allowed to consume the API from their pages.",
+		Links:     []string{"https://www.krakend.io/docs/service-settings/cors/"},
+		Scope:     policy.ScopeService,
+		Namespace: "security/cors",
+		Remediation: map[string]interface{}{
+			"allow_origins": []interface{}{"https://your-frontend.example.com"},
+			"allow_methods": []interface{}{"GET", "POST"},
+			"max_age":       "12h",
+		},
+	},
+	"2.2.3": {
+		Title: "Avoid passing all input headers to the backend",
+		Rationale: "Forwarding every header with input_headers [\"*\"] sends cookies, credentials and spoofed headers " +
+			"to the backends. List only the headers they need.",
+		Links: []string{"https://www.krakend.io/docs/endpoints/parameter-forwarding/"},
+		Scope: policy.ScopeEndpoint,
+	},
+	"2.2.4": {
+		Title: "Avoid passing all input query strings to the backend",
+		Rationale: "Forwarding every query string with input_query_strings [\"*\"] lets the clients reach parameters of the " +
+			"backends that the API does not expose. List only the parameters they need.",
+		Links: []string{"https://www.krakend.io/docs/endpoints/parameter-forwarding/"},
+		Scope: policy.ScopeEndpoint,
+	},
+	"3.1.1": {
+		Title:     "Enable a bot detector",
+		Rationale: "Bots and scrapers consume the capacity of the backends and harvest the content of the API.",
+		Links:     []string{"https://www.krakend.io/docs/throttling/botdetector/"},
+		Scope:     policy.ScopeService,
+		Namespace: "security/bot-detector",
+		Remediation: map[string]interface{}{
+			"empty_user_agent_is_bot": true,
+			"cache_size":              10000,
+		},
+	},
+	"3.1.2": {
+		Title: "Implement a rate-limiting strategy",
+		Rationale: "Endpoints without rate limits let a single client exhaust the gateway and the backends, " +
+			"either by mistake or on purpose.",
+		Links:     []string{"https://www.krakend.io/docs/endpoints/rate-limit/"},
+		Scope:     policy.ScopeEndpoint,
+		Namespace: "qos/ratelimit/router",
+		Remediation: map[string]interface{}{
+			"max_rate":        100,
+			"client_max_rate": 10,
+			"strategy":        "ip",
+		},
+	},
+	"3.1.3": {
+		Title: "Protect your backends with a circuit breaker",
+		Rationale: "A failing backend keeps receiving requests that time out, piling up connections in the gateway. " +
+			"The circuit breaker stops sending traffic to it until it recovers.",
+		Links:     []string{"https://www.krakend.io/docs/backends/circuit-breaker/"},
+		Scope:     policy.ScopeBackend,
+		Namespace: "qos/circuit-breaker",
+		Remediation: map[string]interface{}{
+			"interval":          60,
+			"timeout":           10,
+			"max_errors":        5,
+			"name":              "cb-{{.Method}}-{{.Endpoint}}",
+			"log_status_change": true,
+		},
+	},
+	"3.3.1": {
+		Title: "Set timeouts to below 3 seconds",
+		Rationale: "Long timeouts keep the connections of the slow requests open, exhausting the gateway and the backends " +
+			"under load. Set the timeout of the service and of the endpoints to the time the backends need.",
+		Links: []string{"https://www.krakend.io/docs/endpoints/"},
+		Scope: policy.ScopeService,
+	},
+	"3.3.2": {
+		Title: "Set timeouts to below 5 seconds",
+		Rationale: "Long timeouts keep the connections of the slow requests open, exhausting the gateway and the backends " +
+			"under load. Set the timeout of the service and of the endpoints to the time the backends need.",
+		Links: []string{"https://www.krakend.io/docs/endpoints/"},
+		Scope: policy.ScopeService,
+	},
+	"3.3.3": {
+		Title: "Set timeouts to below 30 seconds",
+		Rationale: "Long timeouts keep the connections of the slow requests open, exhausting the gateway and the backends " +
+			"under load. Set the timeout of the service and of the endpoints to the time the backends need.",
+		Links: []string{"https://www.krakend.io/docs/endpoints/"},
+		Scope: policy.ScopeService,
+	},
+	"3.3.4": {
+		Title: "Set timeouts to below 1 minute",
+		Rationale: "Long timeouts keep the connections of the slow requests open, exhausting the gateway and the backends " +
+			"under load. Set the timeout of the service and of the endpoints to the time the backends need.",
+		Links: []string{"https://www.krakend.io/docs/endpoints/"},
+		Scope: policy.ScopeService,
+	},
+	"4.1.1": {
+		Title:     "Implement a telemetry system for collecting metrics",
+		Rationale: "Without metrics there is no way to monitor the traffic, the latencies and the errors of the gateway.",
+		Links:     []string{"https://www.krakend.io/docs/telemetry/opentelemetry/"},
+		Scope:     policy.ScopeService,
+		Namespace: "telemetry/opentelemetry",
+		Remediation: map[string]interface{}{
+			"exporters": map[string]interface{}{
+				"prometheus": []interface{}{map[string]interface{}{"name": "local_prometheus", "port": 9090}},
+			},
+		},
+	},
+	"4.2.1": {
+		Title:     "Implement a telemetry system for tracing",
+		Rationale: "Traces show where the time of every request goes, across the gateway and the backends.",
+		Links:     []string{"https://www.krakend.io/docs/telemetry/opentelemetry/"},
+		Scope:     policy.ScopeService,
+		Namespace: "telemetry/opentelemetry",
+		Remediation: map[string]interface{}{
+			"exporters": map[string]interface{}{
+				"otlp": []interface{}{map[string]interface{}{"name": "collector", "host": "otel-collector", "port": 4317}},
+			},
+		},
+	},
+	"4.3.1": {
+		Title:     "Use the improved logging component",
+		Rationale: "The logging component sets the level, format and destination of the logs, making them easier to parse.",
+		Links:     []string{"https://www.krakend.io/docs/logging/"},
+		Scope:     policy.ScopeService,
+		Namespace: "telemetry/logging",
+		Remediation: map[string]interface{}{
+			"level":  "INFO",
+			"prefix": "[KRAKEND]",
+			"stdout": true,
+		},
+	},
+}
@@ -0,0 +1,252 @@
+// Package explain describes the audit rules and generates the configuration snippets
+// fixing them for the elements of a configuration
+package explain
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/report"
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+)
+
+// Explanation describes a rule and how to fix it
+type Explanation struct {
+	Rule      string
+	Title     string
+	Rationale string
+	Links     []string
+	// Scope is the kind of element the remediation applies to: service, endpoint
+	// or backend
+	Scope string
+	// Namespace is the extra_config namespace added by the remediation. The elements
+	// already declaring it do not need it.
+	Namespace string
+	// Replaces is the extra_config namespace of the elements the remediation applies
+	// to, when the rule reports its usage
+	Replaces string
+	// Remediation is the value of the namespace to add. Its strings can use the
+	// {{.Endpoint}}, {{.Method}} and {{.URLPattern}} placeholders.
+	Remediation interface{}
+}
+
+// Rendered is the explanation of a rule with the remediations for a configuration
+type Rendered struct {
+	Rule         string    `json:"rule"`
+	Title        string    `json:"title"`
+	Rationale    string    `json:"rationale"`
+	Links        []string  `json:"links"`
+	Remediations []Snippet `json:"remediations"`
+}
+
+// Snippet is the configuration fixing the rule for one of the elements
+type Snippet struct {
+	Location string `json:"location,omitempty"`
+	Snippet  string `json:"snippet"`
+}
+
+// Lookup returns the explanation of the rule, checking the custom rules before the
+// Catalog
+func Lookup(rule string, custom []policy.Rule) (Explanation, bool) {
+	for _, r := range custom {
+		if r.ID != rule {
+			continue
+		}
+		e := Explanation{
+			Rule:      r.ID,
+			Title:     r.Message,
+			Rationale: r.Rationale,
+			Scope:     r.Scope,
+		}
+		if r.URL != "" {
+			e.Links = []string{r.URL}
+		}
+		if len(r.Remediation) > 0 {
+			e.Remediation = r.Remediation
+		}
+		return e, true
+	}
+	e, ok := Catalog[rule]
+	if !ok {
+		return Explanation{}, false
+	}
+	e.Rule = rule
+	if u := report.RuleURL(rule); u != "" {
+		e.Links = append([]string{u}, e.Links...)
+	}
+	return e, true
+}
+
+// Fallback returns the explanation of the rules missing in the Catalog, with the
+// message of their findings and the link to their documentation
+func Fallback(rule, message string) Explanation {
+	e := Explanation{Rule: rule, Title: message}
+	if u := report.RuleURL(rule); u != "" {
+		e.Links = []string{u}
+	}
+	return e
+}
+
+// Locations returns the locations of the elements the rule of the Catalog probably
+// refers to: the ones in its scope missing its namespace, or declaring the namespace it
+// replaces. It is a guess, as the built-in audit does not report the elements. It returns false for the rules
+// of the service, the ones without namespace and the ones not in the Catalog, which
+// cannot be located.
+func Locations(cfg *config.ServiceConfig, rule string) ([]string, bool) {
+	e, ok := Catalog[rule]
+	if !ok || (e.Scope != policy.ScopeEndpoint && e.Scope != policy.ScopeBackend) || (e.Namespace == "" && e.Replaces == "") {
+		return nil, false
+	}
+	var res []string
+	for _, el := range e.elements(cfg, nil) {
+		res = append(res, el.location)
+	}
+	return res, true
+}
+
+// Render generates the remediations of the explanation for the configuration. When
+// locations is not empty, only the elements at them get a remediation; otherwise all
+// the elements in the scope of the explanation needing it do. Without a configuration,
+// the remediation is generated for a sample element.
+func (e Explanation) Render(cfg *config.ServiceConfig, locations []string) (Rendered, error) {
+	r := Rendered{
+		Rule:         e.Rule,
+		Title:        e.Title,
+		Rationale:    e.Rationale,
+		Links:        append([]string{}, e.Links...),
+		Remediations: []Snippet{},
+	}
+	if e.Remediation == nil {
+		return r, nil
+	}
+
+	if cfg == nil {
+		cfg = &config.ServiceConfig{
+			Endpoints: []*config.EndpointConfig{{
+				Endpoint: "/your-endpoint",
+				Method:   "GET",
+				Backend:  []*config.Backend{{URLPattern: "/your-backend"}},
+			}},
+		}
+		locations = nil
+	}
+
+	for _, el := range e.elements(cfg, locations) {
+		s, err := e.snippet(el)
+		if err != nil {
+			return r, err
+		}
+		r.Remediations = append(r.Remediations, Snippet{Location: el.location, Snippet: s})
+	}
+	return r, nil
+}
+
+type element struct {
+	location string
+	endpoint *config.EndpointConfig
+	backend  *config.Backend
+}
+
+func (e Explanation) elements(cfg *config.ServiceConfig, locations []string) []element {
+	var res []element
+	add := func(el element, extra config.ExtraConfig) {
+		if len(locations) > 0 {
+			for _, l := range locations {
+				if l == el.location {
+					res = append(res, el)
+					return
+				}
+			}
+			return
+		}
+		if _, ok := extra[e.Namespace]; ok && e.Namespace != "" {
+			return
+		}
+		if _, ok := extra[e.Replaces]; !ok && e.Replaces != "" {
+			return
+		}
+		res = append(res, el)
+	}
+
+	switch e.Scope {
+	case policy.ScopeService:
+		// the rules of the service can report a namespace declared without the
+		// expected settings, so the remediation always applies
+		res = append(res, element{})
+	case policy.ScopeBackend:
+		for _, ep := range cfg.Endpoints {
+			for _, b := range ep.Backend {
+				add(element{location: policy.BackendLocation(policy.EndpointLocation(ep), b), endpoint: ep, backend: b}, b.ExtraConfig)
+			}
+		}
+	default:
+		for _, ep := range cfg.Endpoints {
+			add(element{location: policy.EndpointLocation(ep), endpoint: ep}, ep.ExtraConfig)
+		}
+	}
+	return res
+}
+
+// snippet renders the remediation as the JSON of the element with the namespace added
+func (e Explanation) snippet(el element) (string, error) {
+	b, err := json.Marshal(e.Remediation)
+	if err != nil {
+		return "", err
+	}
+	value, err := source.Decode(b, source.JSON)
+	if err != nil {
+		return "", err
+	}
+
+	var replacer *strings.Replacer
+	extra := value
+	if e.Namespace != "" {
+		extra = source.Object{{Key: e.Namespace, Value: value}}
+	}
+	doc := source.Object{{Key: "extra_config", Value: extra}}
+
+	if el.endpoint != nil {
+		urlPattern := ""
+		if el.backend != nil {
+			urlPattern = el.backend.URLPattern
+			doc = source.Object{{Key: "url_pattern", Value: urlPattern}, doc[0]}
+			doc = source.Object{{Key: "backend", Value: []interface{}{doc}}}
+		}
+		doc = append(source.Object{
+			{Key: "endpoint", Value: el.endpoint.Endpoint},
+			{Key: "method", Value: el.endpoint.Method},
+		}, doc...)
+		replacer = strings.NewReplacer(
+			"{{.Endpoint}}", el.endpoint.Endpoint,
+			"{{.Method}}", el.endpoint.Method,
+			"{{.URLPattern}}", urlPattern,
+		)
+	}
+	if replacer != nil {
+		replace(doc, replacer)
+	}
+
+	out, err := source.Encode(doc, source.JSON, "  ")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func replace(v interface{}, r *strings.Replacer) interface{} {
+	switch t := v.(type) {
+	case source.Object:
+		for i := range t {
+			t[i].Value = replace(t[i].Value, r)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = replace(t[i], r)
+		}
+	case string:
+		return r.Replace(t)
+	}
+	return v
+}
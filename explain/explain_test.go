@@ -0,0 +1,171 @@
+package explain
+
+import (
+	"testing"
+	"time"
+
+	audit "github.com/krakend/krakend-audit"
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/report"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplanation_Render(t *testing.T) {
+	cfg := &config.ServiceConfig{
+		Endpoints: []*config.EndpointConfig{
+			{
+				Endpoint:    "/public",
+				Method:      "GET",
+				ExtraConfig: config.ExtraConfig{"qos/ratelimit/router": map[string]interface{}{"max_rate": 10.0}},
+				Backend:     []*config.Backend{{URLPattern: "/public"}},
+			},
+			{
+				Endpoint: "/orders",
+				Method:   "POST",
+				Backend:  []*config.Backend{{URLPattern: "/orders/{id}"}},
+			},
+		},
+	}
+	custom := []policy.Rule{{
+		ID:          "ORG-001",
+		Message:     "POST endpoints must be protected with auth/validator",
+		Rationale:   "the orders are private",
+		URL:         "https://wiki.example.com/ORG-001",
+		Scope:       policy.ScopeEndpoint,
+		Remediation: map[string]interface{}{"auth/validator": map[string]interface{}{"audience": []interface{}{"{{.Method}} {{.Endpoint}}"}}},
+	}}
+
+	for name, tc := range map[string]struct {
+		rule      string
+		cfg       *config.ServiceConfig
+		locations []string
+		expected  []Snippet
+	}{
+		"endpoints without the namespace": {
+			rule: "3.1.2",
+			cfg:  cfg,
+			expected: []Snippet{{Location: "POST /orders", Snippet: `{
+  "endpoint": "/orders",
+  "method": "POST",
+  "extra_config": {
+    "qos/ratelimit/router": {
+      "client_max_rate": 10,
+      "max_rate": 100,
+      "strategy": "ip"
+    }
+  }
+}`}},
+		},
+		"backends at the locations": {
+			rule:      "3.1.3",
+			cfg:       cfg,
+			locations: []string{"GET /public -> /public"},
+			expected: []Snippet{{Location: "GET /public -> /public", Snippet: `{
+  "endpoint": "/public",
+  "method": "GET",
+  "backend": [
+    {
+      "url_pattern": "/public",
+      "extra_config": {
+        "qos/circuit-breaker": {
+          "interval": 60,
+          "log_status_change": true,
+          "max_errors": 5,
+          "name": "cb-GET-/public",
+          "timeout": 10
+        }
+      }
+    }
+  ]
+}`}},
+		},
+		"custom rule with placeholders": {
+			rule:      "ORG-001",
+			cfg:       cfg,
+			locations: []string{"POST /orders"},
+			expected: []Snippet{{Location: "POST /orders", Snippet: `{
+  "endpoint": "/orders",
+  "method": "POST",
+  "extra_config": {
+    "auth/validator": {
+      "audience": [
+        "POST /orders"
+      ]
+    }
+  }
+}`}},
+		},
+		"service without a configuration": {
+			rule: "2.2.1",
+			expected: []Snippet{{Snippet: `{
+  "extra_config": {
+    "router": {
+      "hide_version_header": true
+    }
+  }
+}`}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e, ok := Lookup(tc.rule, custom)
+			require.True(t, ok)
+			r, err := e.Render(tc.cfg, tc.locations)
+			require.NoError(t, err)
+			require.Equal(t, tc.rule, r.Rule)
+			require.NotEmpty(t, r.Links)
+			require.Equal(t, tc.expected, r.Remediations)
+		})
+	}
+}
+
+func TestLookup_unknown(t *testing.T) {
+	_, ok := Lookup("9.9.9", nil)
+	require.False(t, ok)
+}
+
+func TestFallback(t *testing.T) {
+	r, err := Fallback("9.9.9", "m").Render(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, Rendered{Rule: "9.9.9", Title: "m", Links: []string{report.DocsURL + "#999"}, Remediations: []Snippet{}}, r)
+}
+
+// TestCatalog_builtinRules audits a configuration breaking as many rules as possible
+// and checks the Catalog explains all the built-in rules reported
+func TestCatalog_builtinRules(t *testing.T) {
+	cfg := &config.ServiceConfig{
+		Version: 3,
+		Timeout: 2 * time.Minute,
+		Debug:   true,
+		Echo:    true,
+		Endpoints: []*config.EndpointConfig{{
+			Endpoint:        "/users/{id}",
+			Method:          "GET",
+			Timeout:         2 * time.Minute,
+			HeadersToPass:   []string{"*"},
+			QueryString:     []string{"*"},
+			ExtraConfig:     config.ExtraConfig{"auth/basic": map[string]interface{}{}},
+			Backend:         []*config.Backend{{URLPattern: "/users/{id}", Host: []string{"http://users"}}},
+			OutputEncoding:  "no-op",
+			ConcurrentCalls: 1,
+		}},
+	}
+	res, err := audit.Audit(cfg, nil, policy.Severities)
+	require.NoError(t, err)
+	for _, r := range res.Recommendations {
+		e, ok := Catalog[r.Rule]
+		if !ok {
+			t.Errorf("the rule %s (%s) is not in the Catalog", r.Rule, r.Message)
+			continue
+		}
+		require.NotEmpty(t, e.Title, r.Rule)
+	}
+
+	for id, e := range Catalog {
+		require.NotEmpty(t, e.Title, id)
+		require.NotEmpty(t, e.Rationale, id)
+		require.NotEmpty(t, e.Links, id)
+		require.NotEmpty(t, report.RuleURL(id), id)
+		require.Contains(t, []string{policy.ScopeService, policy.ScopeEndpoint, policy.ScopeBackend}, e.Scope, id)
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/krakend/krakend-cobra/v2/graph"
+	"github.com/spf13/cobra"
+)
+
+func graphFunc(cmd *cobra.Command, _ []string) {
+	if cfgFile == "" {
+		cmd.Println(errorMsg("Please, provide the path to the configuration file with --config or see all the options with --help"))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	cfg, err := parser.Parse(cfgFile)
+	if err != nil {
+		cmd.Println(errorMsg("ERROR parsing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	var marks []string
+	for _, ns := range strings.Split(strings.ReplaceAll(graphMarks, " ", ""), ",") {
+		if ns != "" {
+			marks = append(marks, ns)
+		}
+	}
+
+	g := graph.New(cfg, graph.Options{
+		GroupByHost:      graphGroupByHost,
+		CollapseBackends: graphCollapse,
+		Mark:             marks,
+	})
+
+	switch graphFormat {
+	case "dot":
+		err = g.DOT(cmd.OutOrStdout())
+	case "mermaid":
+		err = g.Mermaid(cmd.OutOrStdout())
+	default:
+		err = fmt.Errorf("unknown format %s", graphFormat)
+	}
+	if err != nil {
+		cmd.Println(errorMsg("ERROR exporting the graph:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+}
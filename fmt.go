@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/spf13/cobra"
+)
+
+func fmtFunc(cmd *cobra.Command, _ []string) {
+	if cfgFile == "" {
+		cmd.Println(errorMsg("Please, provide the path to the configuration file with --config or see all the options with --help"))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	original, formatted, err := formatFile(cfgFile)
+	if err != nil {
+		cmd.Println(errorMsg("ERROR formatting the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	if bytes.Equal(original, formatted) {
+		return
+	}
+
+	if fmtDiff {
+		diff, err := unifiedDiff(cfgFile, original, formatted)
+		if err != nil {
+			cmd.Println(errorMsg("ERROR generating the diff:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+		fmt.Fprint(cmd.OutOrStdout(), diff)
+	}
+
+	if fmtCheck {
+		cmd.Println(errorMsg(fmt.Sprintf("%s is not formatted", cfgFile)))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	if fmtDiff {
+		return
+	}
+
+	info, err := os.Stat(cfgFile)
+	if err == nil {
+		err = os.WriteFile(cfgFile, formatted, info.Mode().Perm())
+	}
+	if err != nil {
+		cmd.Println(errorMsg("ERROR writing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+}
+
+// formatFile returns the content of the file and its canonical form. The keys are
+// ordered by the embed JSON schema, if any. The comments of the YAML files are kept,
+// and the TOML files with comments are not formatted, as they would be lost.
+func formatFile(path string) ([]byte, []byte, error) {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	layout := source.DefaultLayout
+	if rawEmbedSchema != "" {
+		layout, err = source.SchemaLayout([]byte(rawEmbedSchema))
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing the embed schema: %w", err)
+		}
+	}
+
+	format := source.FormatFromPath(path)
+	var formatted []byte
+	switch format {
+	case source.YAML:
+		formatted, err = layout.CanonicalYAML(original, fmtSortEndpoints, fmtIndent)
+	case source.TOML:
+		if source.HasTOMLComments(original) {
+			return nil, nil, fmt.Errorf("'%s': the TOML files with comments cannot be formatted without losing them", path)
+		}
+		fallthrough
+	default:
+		var v interface{}
+		v, err = source.Decode(original, format)
+		if err == nil {
+			formatted, err = source.Encode(layout.Canonical(v, fmtSortEndpoints), format, fmtIndent)
+		}
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("'%s': %w", path, err)
+	}
+	return original, formatted, nil
+}
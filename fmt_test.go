@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fmtFunc_diff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "krakend.json")
+	original := []byte("{\"name\": \"test\", \"version\": 3}\n")
+	require.NoError(t, os.WriteFile(path, original, 0o600))
+
+	defer func(f string, diff, check bool) { cfgFile, fmtDiff, fmtCheck = f, diff, check }(cfgFile, fmtDiff, fmtCheck)
+	cfgFile, fmtDiff, fmtCheck = path, true, false
+
+	stdout, stderr := captureStdout(t, fmtFunc)
+	require.Contains(t, stdout, "--- "+path+"\n+++ "+path+"\n")
+	require.Contains(t, stdout, "+  \"version\": 3,\n")
+	require.Empty(t, stderr)
+
+	// the diff mode does not modify the file
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, original, b)
+}
@@ -0,0 +1,346 @@
+// Package graph builds the dependency graph between the endpoints, async agents,
+// backends and hosts of a KrakenD configuration and renders it as Graphviz DOT or
+// Mermaid diagrams
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/luraproject/lura/v2/config"
+)
+
+// Kinds of nodes
+const (
+	KindEndpoint = "endpoint"
+	KindAgent    = "agent"
+	KindBackend  = "backend"
+	KindHost     = "host"
+)
+
+// Options customizes the graph
+type Options struct {
+	// GroupByHost draws the backends inside a group per host instead of linking them to host nodes
+	GroupByHost bool
+	// CollapseBackends merges the backends with the same method, URL pattern and hosts
+	CollapseBackends bool
+	// Mark lists the extra_config namespaces to highlight in the nodes defining them
+	Mark []string
+}
+
+// Node is an element of the configuration
+type Node struct {
+	ID    string
+	Kind  string
+	Label string
+	// Group is the host group of the backend nodes when grouping by host
+	Group string
+	// Marks are the selected namespaces present in the extra_config of the element
+	Marks []string
+}
+
+// Edge links two nodes
+type Edge struct {
+	From string
+	To   string
+}
+
+// Graph is the set of nodes and edges of a configuration
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+	Marks []string
+	// Grouped is true when the backends are grouped by host
+	Grouped bool
+}
+
+// New walks the configuration and returns its graph
+func New(cfg config.ServiceConfig, opts Options) Graph {
+	b := builder{
+		opts:     opts,
+		g:        Graph{Marks: opts.Mark, Grouped: opts.GroupByHost},
+		backends: map[string]string{},
+		hosts:    map[string]string{},
+		edges:    map[Edge]struct{}{},
+	}
+
+	for i, e := range cfg.Endpoints {
+		id := fmt.Sprintf("e%d", i)
+		b.g.Nodes = append(b.g.Nodes, Node{
+			ID:    id,
+			Kind:  KindEndpoint,
+			Label: e.Method + " " + e.Endpoint,
+			Marks: b.marks(e.ExtraConfig),
+		})
+		for _, backend := range e.Backend {
+			b.addBackend(id, backend)
+		}
+	}
+
+	for i, a := range cfg.AsyncAgents {
+		id := fmt.Sprintf("a%d", i)
+		b.g.Nodes = append(b.g.Nodes, Node{
+			ID:    id,
+			Kind:  KindAgent,
+			Label: "agent " + a.Name,
+			Marks: b.marks(a.ExtraConfig),
+		})
+		for _, backend := range a.Backend {
+			b.addBackend(id, backend)
+		}
+	}
+
+	return b.g
+}
+
+type builder struct {
+	opts      Options
+	g         Graph
+	nBackends int
+	backends  map[string]string
+	hosts     map[string]string
+	edges     map[Edge]struct{}
+}
+
+func (b *builder) addBackend(from string, backend *config.Backend) {
+	hosts := hostLabels(backend)
+	key := backend.Method + " " + backend.URLPattern + " " + strings.Join(hosts, ",")
+	if b.opts.CollapseBackends {
+		if id, ok := b.backends[key]; ok {
+			b.addEdge(from, id)
+			return
+		}
+	}
+
+	id := fmt.Sprintf("b%d", b.nBackends)
+	b.nBackends++
+	b.backends[key] = id
+
+	n := Node{
+		ID:    id,
+		Kind:  KindBackend,
+		Label: strings.TrimSpace(backend.Method + " " + backend.URLPattern),
+		Marks: b.marks(backend.ExtraConfig),
+	}
+	if b.opts.GroupByHost {
+		n.Group = strings.Join(hosts, ", ")
+		if n.Group == "" {
+			n.Group = "no host"
+		}
+	}
+	b.g.Nodes = append(b.g.Nodes, n)
+	b.addEdge(from, id)
+	if b.opts.GroupByHost {
+		return
+	}
+
+	for _, h := range hosts {
+		hostID, ok := b.hosts[h]
+		if !ok {
+			hostID = fmt.Sprintf("h%d", len(b.hosts))
+			b.hosts[h] = hostID
+			b.g.Nodes = append(b.g.Nodes, Node{ID: hostID, Kind: KindHost, Label: h})
+		}
+		b.addEdge(id, hostID)
+	}
+}
+
+func (b *builder) addEdge(from, to string) {
+	e := Edge{From: from, To: to}
+	if _, ok := b.edges[e]; ok {
+		return
+	}
+	b.edges[e] = struct{}{}
+	b.g.Edges = append(b.g.Edges, e)
+}
+
+func (b *builder) marks(cfg config.ExtraConfig) []string {
+	var res []string
+	for _, ns := range b.opts.Mark {
+		if _, ok := cfg[ns]; ok {
+			res = append(res, ns)
+		}
+	}
+	return res
+}
+
+// hostLabels returns the hosts of the backend, followed by the service discovery
+// mechanism when it is not the static one. The hosts resolved by the service discovery
+// get the sd_scheme, as the gateway does.
+func hostLabels(backend *config.Backend) []string {
+	hosts := make([]string, len(backend.Host))
+	for i, h := range backend.Host {
+		if backend.SD != "" && backend.SD != "static" {
+			scheme := backend.SDScheme
+			if scheme == "" {
+				scheme = "http"
+			}
+			if !strings.Contains(h, "://") {
+				h = scheme + "://" + h
+			}
+			h = fmt.Sprintf("%s (%s)", h, backend.SD)
+		}
+		hosts[i] = h
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// groups returns the names of the host groups in order of appearance and the nodes of each group
+func (g Graph) groups() ([]string, map[string][]Node) {
+	var names []string
+	nodes := map[string][]Node{}
+	for _, n := range g.Nodes {
+		if n.Group == "" {
+			continue
+		}
+		if _, ok := nodes[n.Group]; !ok {
+			names = append(names, n.Group)
+		}
+		nodes[n.Group] = append(nodes[n.Group], n)
+	}
+	return names, nodes
+}
+
+var palette = []string{"#f4a261", "#2a9d8f", "#e9c46a", "#e76f51", "#8ab17d", "#9d4edd", "#48cae4", "#ff70a6"}
+
+func (g Graph) markColor(mark string) string {
+	for i, m := range g.Marks {
+		if m == mark {
+			return palette[i%len(palette)]
+		}
+	}
+	return ""
+}
+
+func label(n Node) string {
+	if len(n.Marks) == 0 {
+		return n.Label
+	}
+	return n.Label + "\n[" + strings.Join(n.Marks, ", ") + "]"
+}
+
+var dotShapes = map[string]string{
+	KindEndpoint: "box",
+	KindAgent:    "cds",
+	KindBackend:  "ellipse",
+	KindHost:     "cylinder",
+}
+
+// DOT writes the graph in the Graphviz DOT language
+func (g Graph) DOT(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("digraph krakend {\n")
+	ew.printf("\trankdir=LR;\n")
+
+	writeNode := func(prefix string, n Node) {
+		attrs := fmt.Sprintf("label=%s, shape=%s", dotQuote(label(n)), dotShapes[n.Kind])
+		if len(n.Marks) > 0 {
+			attrs += fmt.Sprintf(", style=filled, fillcolor=%s", dotQuote(g.markColor(n.Marks[0])))
+		}
+		ew.printf("%s%s [%s];\n", prefix, n.ID, attrs)
+	}
+
+	if g.Grouped {
+		names, groups := g.groups()
+		for i, name := range names {
+			ew.printf("\tsubgraph cluster_%d {\n", i)
+			ew.printf("\t\tlabel=%s;\n", dotQuote(name))
+			for _, n := range groups[name] {
+				writeNode("\t\t", n)
+			}
+			ew.printf("\t}\n")
+		}
+	}
+	for _, n := range g.Nodes {
+		if g.Grouped && n.Kind == KindBackend {
+			continue
+		}
+		writeNode("\t", n)
+	}
+	for _, e := range g.Edges {
+		ew.printf("\t%s -> %s;\n", e.From, e.To)
+	}
+	ew.printf("}\n")
+	return ew.err
+}
+
+// Mermaid writes the graph as a Mermaid flowchart
+func (g Graph) Mermaid(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("flowchart LR\n")
+
+	writeNode := func(prefix string, n Node) {
+		l := mermaidQuote(label(n))
+		switch n.Kind {
+		case KindEndpoint:
+			ew.printf("%s%s[%s]\n", prefix, n.ID, l)
+		case KindAgent:
+			ew.printf("%s%s>%s]\n", prefix, n.ID, l)
+		case KindBackend:
+			ew.printf("%s%s(%s)\n", prefix, n.ID, l)
+		case KindHost:
+			ew.printf("%s%s[(%s)]\n", prefix, n.ID, l)
+		}
+	}
+
+	if g.Grouped {
+		names, groups := g.groups()
+		for i, name := range names {
+			ew.printf("\tsubgraph g%d[%s]\n", i, mermaidQuote(name))
+			for _, n := range groups[name] {
+				writeNode("\t\t", n)
+			}
+			ew.printf("\tend\n")
+		}
+	}
+	for _, n := range g.Nodes {
+		if g.Grouped && n.Kind == KindBackend {
+			continue
+		}
+		writeNode("\t", n)
+	}
+	for _, e := range g.Edges {
+		ew.printf("\t%s --> %s\n", e.From, e.To)
+	}
+
+	for i, m := range g.Marks {
+		var ids []string
+		for _, n := range g.Nodes {
+			if len(n.Marks) > 0 && n.Marks[0] == m {
+				ids = append(ids, n.ID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		ew.printf("\tclassDef mark%d fill:%s\n", i, g.markColor(m))
+		ew.printf("\tclass %s mark%d\n", strings.Join(ids, ","), i)
+	}
+	return ew.err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return `"` + strings.ReplaceAll(s, "\n", "<br>") + `"`
+}
+
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
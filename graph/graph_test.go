@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() config.ServiceConfig {
+	users := &config.Backend{Method: "GET", URLPattern: "/users", Host: []string{"http://users"}}
+	return config.ServiceConfig{
+		Endpoints: []*config.EndpointConfig{
+			{
+				Method:      "GET",
+				Endpoint:    "/me",
+				ExtraConfig: config.ExtraConfig{"auth/validator": map[string]interface{}{}},
+				Backend: []*config.Backend{
+					users,
+					{Method: "GET", URLPattern: "/profile", Host: []string{"profile.service"}, SD: "dns", SDScheme: "https"},
+				},
+			},
+			{
+				Method:   "GET",
+				Endpoint: "/users",
+				Backend:  []*config.Backend{{Method: "GET", URLPattern: "/users", Host: []string{"http://users"}}},
+			},
+		},
+	}
+}
+
+func TestGraph_DOT(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New(testConfig(), Options{Mark: []string{"auth/validator"}}).DOT(&buf))
+	require.Equal(t, `digraph krakend {
+	rankdir=LR;
+	e0 [label="GET /me\n[auth/validator]", shape=box, style=filled, fillcolor="#f4a261"];
+	b0 [label="GET /users", shape=ellipse];
+	h0 [label="http://users", shape=cylinder];
+	b1 [label="GET /profile", shape=ellipse];
+	h1 [label="https://profile.service (dns)", shape=cylinder];
+	e1 [label="GET /users", shape=box];
+	b2 [label="GET /users", shape=ellipse];
+	e0 -> b0;
+	b0 -> h0;
+	e0 -> b1;
+	b1 -> h1;
+	e1 -> b2;
+	b2 -> h0;
+}
+`, buf.String())
+}
+
+func TestGraph_Mermaid(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New(testConfig(), Options{GroupByHost: true, CollapseBackends: true, Mark: []string{"auth/validator"}}).Mermaid(&buf))
+	require.Equal(t, `flowchart LR
+	subgraph g0["http://users"]
+		b0("GET /users")
+	end
+	subgraph g1["https://profile.service (dns)"]
+		b1("GET /profile")
+	end
+	e0["GET /me<br>[auth/validator]"]
+	e1["GET /users"]
+	e0 --> b0
+	e0 --> b1
+	e1 --> b0
+	classDef mark0 fill:#f4a261
+	class e0 mark0
+`, buf.String())
+}
@@ -0,0 +1,150 @@
+// Package history stores snapshots of the audit results to track their evolution
+// between runs
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	filePrefix = "audit-"
+	fileSuffix = ".json"
+	timeLayout = "20060102T150405.000000000Z"
+)
+
+// Snapshot is the result of an audit run
+type Snapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+	Files     []File    `json:"files"`
+}
+
+// File is the result of the audit of a configuration file
+type File struct {
+	File     string    `json:"file"`
+	Hash     string    `json:"hash"`
+	Score    int       `json:"score"`
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is a recommendation of the audit
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Delta is the difference between the results of a file in two snapshots
+type Delta struct {
+	Previous      time.Time `json:"previous"`
+	New           []Finding `json:"new"`
+	Fixed         []Finding `json:"fixed"`
+	Unchanged     []Finding `json:"unchanged"`
+	PreviousScore int       `json:"previous_score"`
+	Score         int       `json:"score"`
+	ScoreChange   int       `json:"score_change"`
+	ConfigChanged bool      `json:"config_changed"`
+}
+
+// Hash returns the hash identifying the content of a configuration file
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Latest returns the most recent snapshot stored in the directory, or nil when there
+// are none
+func Latest(dir string) (*Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), filePrefix) && strings.HasSuffix(e.Name(), fileSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	b, err := os.ReadFile(filepath.Join(dir, names[len(names)-1]))
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save stores the snapshot in the directory, creating it when it does not exist, and
+// returns the path of the file written
+func Save(dir string, s Snapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, filePrefix+s.Timestamp.UTC().Format(timeLayout)+fileSuffix)
+	return path, os.WriteFile(path, b, 0o644) // skipcq: GSC-G306
+}
+
+// File returns the results of the configuration file in the snapshot
+func (s Snapshot) File(name string) (File, bool) {
+	for _, f := range s.Files {
+		if f.File == name {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// Compare returns the changes from the previous results of a file to the current ones
+func Compare(previous time.Time, prev, cur File) Delta {
+	d := Delta{
+		Previous:      previous,
+		New:           []Finding{},
+		Fixed:         []Finding{},
+		Unchanged:     []Finding{},
+		PreviousScore: prev.Score,
+		Score:         cur.Score,
+		ScoreChange:   cur.Score - prev.Score,
+		ConfigChanged: prev.Hash != cur.Hash,
+	}
+
+	before := map[Finding]int{}
+	for _, f := range prev.Findings {
+		before[f]++
+	}
+	for _, f := range cur.Findings {
+		if before[f] > 0 {
+			before[f]--
+			d.Unchanged = append(d.Unchanged, f)
+			continue
+		}
+		d.New = append(d.New, f)
+	}
+	for _, f := range prev.Findings {
+		if before[f] > 0 {
+			before[f]--
+			d.Fixed = append(d.Fixed, f)
+		}
+	}
+	return d
+}
@@ -0,0 +1,52 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLatest(t *testing.T) {
+	dir := t.TempDir() + "/history"
+
+	s, err := Latest(dir)
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	t0 := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := Save(dir, Snapshot{Timestamp: t0.Add(time.Duration(i) * time.Hour), Files: []File{{File: "krakend.json", Score: 90 + i}}})
+		require.NoError(t, err)
+	}
+
+	s, err = Latest(dir)
+	require.NoError(t, err)
+	require.Equal(t, t0.Add(2*time.Hour), s.Timestamp)
+	f, ok := s.File("krakend.json")
+	require.True(t, ok)
+	require.Equal(t, 92, f.Score)
+	_, ok = s.File("other.json")
+	require.False(t, ok)
+}
+
+func TestCompare(t *testing.T) {
+	a := Finding{Rule: "1.1.1", Severity: "HIGH", Message: "a"}
+	b := Finding{Rule: "2.1.1", Severity: "LOW", Message: "b"}
+	c := Finding{Rule: "3.1.1", Severity: "MEDIUM", Message: "c"}
+
+	prev := File{File: "krakend.json", Hash: Hash([]byte("v1")), Score: 70, Findings: []Finding{a, b, b}}
+	cur := File{File: "krakend.json", Hash: Hash([]byte("v2")), Score: 85, Findings: []Finding{b, c}}
+	at := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, Delta{
+		Previous:      at,
+		New:           []Finding{c},
+		Fixed:         []Finding{a, b},
+		Unchanged:     []Finding{b},
+		PreviousScore: 70,
+		Score:         85,
+		ScoreChange:   15,
+		ConfigChanged: true,
+	}, Compare(at, prev, cur))
+}
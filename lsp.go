@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/krakend/krakend-cobra/v2/lsp"
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/spf13/cobra"
+)
+
+// Sources of the diagnostics published by the language server
+const (
+	lspSourceCheck = "krakend check"
+	lspSourceLint  = "krakend lint"
+	lspSourceAudit = "krakend audit"
+)
+
+// lspSeverities maps the severities of the audit to the ones of the diagnostics, so
+// the recommendations are not shown as errors of the configuration
+var lspSeverities = map[string]int{
+	"CRITICAL": lsp.SeverityWarning,
+	"HIGH":     lsp.SeverityWarning,
+	"MEDIUM":   lsp.SeverityInformation,
+	"LOW":      lsp.SeverityHint,
+}
+
+func lspFunc(cmd *cobra.Command, _ []string) {
+	opts, label, err := newAuditOptions()
+	if err != nil {
+		cmd.PrintErrln(errorMsg(label) + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	var sch *jsonschema.Schema
+	var namespaces map[string]lsp.Namespace
+	if rawEmbedSchema != "" {
+		sch, label, err = compileEmbedSchema()
+		if err != nil {
+			cmd.PrintErrln(errorMsg(label) + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+		namespaces, err = lsp.Namespaces([]byte(rawEmbedSchema))
+		if err != nil {
+			cmd.PrintErrln(errorMsg("ERROR parsing the embed schema:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+	}
+
+	s := &lsp.Server{
+		Diagnose: func(path string, content []byte) []lsp.Problem {
+			return lspDiagnostics(path, content, sch, opts)
+		},
+		Namespaces: namespaces,
+		Log:        cmd.ErrOrStderr(),
+	}
+	if err := s.Serve(cmd.InOrStdin(), cmd.OutOrStdout()); err != nil {
+		cmd.PrintErrln(errorMsg("ERROR serving the language server:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+	}
+}
+
+// lspDiagnostics runs the checks and the audit on the content of the configuration
+// file. The unsaved content is parsed with source.Parse instead of configParser(). The
+// templates of the flexible configuration cannot be checked until they are
+// rendered, so their saved version is parsed instead.
+func lspDiagnostics(path string, content []byte, sch *jsonschema.Schema, opts auditOptions) []lsp.Problem {
+	format := source.FormatFromPath(path)
+	v, err := source.Decode(content, format)
+	if err != nil {
+		if bytes.Contains(content, []byte("{{")) {
+			return lspTemplateDiagnostics(path, opts)
+		}
+		return []lsp.Problem{{Position: decodeErrorPosition(content, path, err), Severity: lsp.SeverityError, Source: lspSourceCheck, Message: err.Error()}}
+	}
+
+	positions, err := source.Locate(content, format, path)
+	if err != nil {
+		positions = source.Positions{"": {File: path}}
+	}
+	sm := sourceMap{positions: positions}
+
+	var problems []lsp.Problem
+	if sch != nil {
+		problems = append(problems, lintProblems(sm, v, sch)...)
+	}
+
+	cfg, err := source.Parse(v)
+	if err != nil {
+		return append(problems, lsp.Problem{Position: source.Position{File: path}, Severity: lsp.SeverityError, Source: lspSourceCheck, Message: err.Error()})
+	}
+	return append(problems, serviceProblems(cfg, sm, opts)...)
+}
+
+// lspTemplateDiagnostics checks the saved template of the flexible configuration
+func lspTemplateDiagnostics(path string, opts auditOptions) []lsp.Problem {
+	p := configParser()
+	cfg, err := p.Parse(path)
+	if err != nil {
+		return []lsp.Problem{{Position: source.Position{File: path}, Severity: lsp.SeverityError, Source: lspSourceCheck, Message: err.Error()}}
+	}
+	sm := newSourceMap(p, path)
+	return serviceProblems(cfg, sm, opts)
+}
+
+// lintProblems validates the decoded content against the schema
+func lintProblems(sm sourceMap, v interface{}, sch *jsonschema.Schema) []lsp.Problem {
+	b, err := source.Encode(v, source.JSON, "")
+	if err != nil {
+		return nil
+	}
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil
+	}
+	err = sch.Validate(raw)
+	if err == nil {
+		return nil
+	}
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return []lsp.Problem{{Position: sm.position(), Severity: lsp.SeverityError, Source: lspSourceLint, Message: err.Error()}}
+	}
+	var problems []lsp.Problem
+	for _, e := range sm.schemaErrors(verr) {
+		problems = append(problems, lsp.Problem{Position: e.Position, Severity: lsp.SeverityError, Source: lspSourceLint, Message: e.err.Error()})
+	}
+	return problems
+}
+
+// serviceProblems runs CustomValidationFunc and the audit on the parsed configuration
+func serviceProblems(cfg config.ServiceConfig, sm sourceMap, opts auditOptions) []lsp.Problem {
+	var problems []lsp.Problem
+	for _, err := range CustomValidationFunc(cfg) {
+		problems = append(problems, lsp.Problem{Position: sm.configError(err), Severity: lsp.SeverityError, Source: lspSourceCheck, Message: err.Error()})
+	}
+
+	data, _, err := auditService(cfg, sm, opts)
+	if err != nil {
+		return append(problems, lsp.Problem{Position: sm.position(), Severity: lsp.SeverityError, Source: lspSourceAudit, Message: err.Error()})
+	}
+	for _, r := range data.Recommendations {
+		p := lsp.Problem{Severity: lspSeverities[r.Severity], Code: r.Rule, Source: lspSourceAudit, Message: r.Message}
+		if p.Severity == 0 {
+			p.Severity = lsp.SeverityInformation
+		}
+		if r.Position != nil {
+			p.Position = *r.Position
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+var (
+	lineColumnErrorPattern = regexp.MustCompile(`^\((\d+), (\d+)\)`)
+	lineErrorPattern       = regexp.MustCompile(`line (\d+)`)
+)
+
+// decodeErrorPosition returns the position of the syntax error reported by the
+// decoders of the JSON, YAML and TOML files
+func decodeErrorPosition(content []byte, path string, err error) source.Position {
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) && serr.Offset <= int64(len(content)) {
+		before := content[:serr.Offset]
+		lineStart := bytes.LastIndexByte(before, '\n') + 1
+		return source.Position{File: path, Line: bytes.Count(before, []byte("\n")) + 1, Column: utf8.RuneCount(before[lineStart:]) + 1}
+	}
+	if m := lineColumnErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		column, _ := strconv.Atoi(m[2])
+		return source.Position{File: path, Line: line, Column: column}
+	}
+	if m := lineErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return source.Position{File: path, Line: line, Column: 1}
+	}
+	return source.Position{File: path}
+}
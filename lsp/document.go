@@ -0,0 +1,303 @@
+package lsp
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+)
+
+// document is an open configuration file
+type document struct {
+	uri    string
+	path   string
+	format string
+	text   []byte
+	lines  [][]byte
+}
+
+func newDocument(uri string, text []byte) *document {
+	path := uriToPath(uri)
+	return &document{uri: uri, path: path, format: source.FormatFromPath(path), text: text, lines: bytes.Split(text, []byte("\n"))}
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func (d *document) line(n int) []byte {
+	if n < 0 || n >= len(d.lines) {
+		return nil
+	}
+	return bytes.TrimSuffix(d.lines[n], []byte("\r"))
+}
+
+// toLSP converts the position of the source, with 1-based lines and columns counted
+// in characters, to the position of the protocol, counted in UTF-16 units
+func (d *document) toLSP(p source.Position) Position {
+	line := d.line(p.Line - 1)
+	units := 0
+	for i := 1; i < p.Column && len(line) > 0; i++ {
+		r, size := utf8.DecodeRune(line)
+		units += utf16.RuneLen(r)
+		line = line[size:]
+	}
+	return Position{Line: p.Line - 1, Character: units}
+}
+
+// toSource converts the position of the protocol to the one of the source
+func (d *document) toSource(p Position) source.Position {
+	line := d.line(p.Line)
+	column, units := 1, 0
+	for units < p.Character && len(line) > 0 {
+		r, size := utf8.DecodeRune(line)
+		units += utf16.RuneLen(r)
+		line = line[size:]
+		column++
+	}
+	return source.Position{File: d.path, Line: p.Line + 1, Column: column}
+}
+
+// byteOffset returns the offset in the line of the position of the protocol
+func (d *document) byteOffset(p Position) int {
+	line := d.line(p.Line)
+	offset, units := 0, 0
+	for units < p.Character && offset < len(line) {
+		r, size := utf8.DecodeRune(line[offset:])
+		units += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// token returns the key or value at the position, without quotes, and its range
+func (d *document) token(p Position) (string, Range) {
+	line := d.line(p.Line)
+	offset := d.byteOffset(p)
+	isDelim := func(b byte) bool { return strings.IndexByte(" \t\"':,{}[]=", b) >= 0 }
+	start, end := offset, offset
+	for start > 0 && !isDelim(line[start-1]) {
+		start--
+	}
+	for end < len(line) && !isDelim(line[end]) {
+		end++
+	}
+	r := Range{
+		Start: Position{Line: p.Line, Character: utf16Len(line[:start])},
+		End:   Position{Line: p.Line, Character: utf16Len(line[:end])},
+	}
+	return string(line[start:end]), r
+}
+
+// tokenRange returns the range of the key or value starting at the position
+func (d *document) tokenRange(p source.Position) Range {
+	start := d.toLSP(p)
+	line := d.line(start.Line)
+	offset := d.byteOffset(start)
+	end := offset
+	if end < len(line) && (line[end] == '"' || line[end] == '\'') {
+		quote := line[end]
+		end++
+		for end < len(line) && line[end] != quote {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end+1, len(line))
+	} else {
+		for end < len(line) && strings.IndexByte(" \t:,=", line[end]) < 0 {
+			end++
+		}
+	}
+	return Range{Start: start, End: Position{Line: start.Line, Character: utf16Len(line[:end])}}
+}
+
+func utf16Len(b []byte) int {
+	n := 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		n += utf16.RuneLen(r)
+		b = b[size:]
+	}
+	return n
+}
+
+// extraConfigScope returns the scope of the extra_config object where the position
+// expects a key, and whether the key is being written inside quotes
+func (d *document) extraConfigScope(p Position) (string, bool, bool) {
+	switch d.format {
+	case source.JSON:
+		return d.jsonExtraConfigScope(p)
+	case source.YAML:
+		scope, ok := d.yamlExtraConfigScope(p)
+		return scope, false, ok
+	default:
+		return "", false, false
+	}
+}
+
+// jsonExtraConfigScope scans the content up to the position, tolerating the invalid
+// documents being edited
+func (d *document) jsonExtraConfigScope(p Position) (string, bool, bool) {
+	type frame struct {
+		key    string
+		object bool
+	}
+	var stack []frame
+	key, last, expectKey, inString := "", "", false, false
+
+	end := 0
+	for i := 0; i < p.Line && i < len(d.lines); i++ {
+		end += len(d.lines[i]) + 1
+	}
+	end = min(end+d.byteOffset(p), len(d.text))
+
+	for i := 0; i < end; i++ {
+		switch d.text[i] {
+		case '"':
+			j := i + 1
+			for j < end && d.text[j] != '"' {
+				if d.text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= end {
+				inString = true
+				i = end
+				continue
+			}
+			last = string(d.text[i+1 : j])
+			i = j
+		case ':':
+			key, expectKey = last, false
+		case '{', '[':
+			stack = append(stack, frame{key: key, object: d.text[i] == '{'})
+			key, expectKey = "", d.text[i] == '{'
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			key, expectKey = "", false
+		case ',':
+			key = ""
+			expectKey = len(stack) > 0 && stack[len(stack)-1].object
+		}
+	}
+
+	if len(stack) == 0 || !expectKey {
+		return "", false, false
+	}
+	top := stack[len(stack)-1]
+	if !top.object || top.key != "extra_config" {
+		return "", false, false
+	}
+	keys := make([]string, 0, len(stack)-1)
+	for _, f := range stack[:len(stack)-1] {
+		keys = append(keys, f.key)
+	}
+	return scopeOf(keys), inString, true
+}
+
+// yamlExtraConfigScope looks for the parent keys of the position by their indentation
+func (d *document) yamlExtraConfigScope(p Position) (string, bool) {
+	line := string(d.line(p.Line))
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if strings.TrimSpace(line) == "" {
+		indent = d.byteOffset(p)
+	}
+
+	var keys []string
+	for n := p.Line - 1; n >= 0 && indent > 0; n-- {
+		l := string(d.line(n))
+		content := strings.TrimLeft(l, " ")
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+		lineIndent := len(l) - len(content)
+		if item, ok := strings.CutPrefix(content, "- "); ok {
+			// the keys of the items of a sequence are indented after the dash
+			if lineIndent+2 < indent {
+				content = item
+				lineIndent += 2
+			}
+		}
+		if lineIndent >= indent {
+			continue
+		}
+		k, _, _ := strings.Cut(content, ":")
+		keys = append([]string{strings.Trim(strings.TrimPrefix(k, "- "), `"'`)}, keys...)
+		indent = lineIndent
+	}
+
+	if len(keys) == 0 || keys[len(keys)-1] != "extra_config" {
+		return "", false
+	}
+	return scopeOf(keys[:len(keys)-1]), true
+}
+
+// scopeOf returns the scope of the element at the path of keys
+func scopeOf(keys []string) string {
+	scope := ScopeService
+	for _, k := range keys {
+		switch k {
+		case "endpoints":
+			scope = ScopeEndpoint
+		case "backend":
+			scope = ScopeBackend
+		case "async_agent":
+			scope = ScopeAgent
+		}
+	}
+	return scope
+}
+
+// backendHost returns the position of the hosts of the backend at the position: the
+// ones of the backend or, when it has none, the default hosts of the service
+func (d *document) backendHost(p Position) (source.Position, bool) {
+	positions, err := source.Locate(d.text, d.format, d.path)
+	if err != nil {
+		return source.Position{}, false
+	}
+	cursor := d.toSource(p)
+	current, best := "", source.Position{}
+	for ptr, pos := range positions {
+		if before(cursor, pos) || before(pos, best) {
+			continue
+		}
+		if pos == best && len(ptr) < len(current) {
+			continue
+		}
+		current, best = ptr, pos
+	}
+
+	segments := strings.Split(current, "/")
+	for i := len(segments) - 1; i >= 3; i-- {
+		if segments[i-1] != "backend" || (segments[i-3] != "endpoints" && segments[i-3] != "async_agent") {
+			continue
+		}
+		if pos, ok := positions[strings.Join(segments[:i+1], "/")+"/host"]; ok {
+			return pos, true
+		}
+		pos, ok := positions["/host"]
+		return pos, ok
+	}
+	return source.Position{}, false
+}
+
+func before(a, b source.Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
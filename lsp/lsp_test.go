@@ -0,0 +1,188 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "$id": "https://example.com/krakend.json",
+  "type": "object",
+  "properties": {
+    "extra_config": {"$ref": "service_extra_config.json"},
+    "endpoints": {"type": "array", "items": {"$ref": "#/definitions/endpoint"}}
+  },
+  "definitions": {
+    "endpoint": {
+      "type": "object",
+      "properties": {
+        "extra_config": {"properties": {"qos/ratelimit/router": {"$ref": "#/definitions/ratelimit"}}},
+        "backend": {"type": "array", "items": {"properties": {
+          "extra_config": {"properties": {"qos/circuit-breaker": {"title": "Circuit breaker"}}}
+        }}}
+      }
+    },
+    "ratelimit": {"title": "Rate limit", "description": "Limits the requests per second."},
+    "service": {
+      "$id": "https://example.com/service_extra_config.json",
+      "properties": {
+        "router": {"title": "Router"},
+        "qos/ratelimit/router": {"title": "Rate limit"}
+      }
+    }
+  }
+}`
+
+func TestNamespaces(t *testing.T) {
+	namespaces, err := Namespaces([]byte(testSchema))
+	require.NoError(t, err)
+	require.Equal(t, map[string]Namespace{
+		"router":               {Title: "Router", Scopes: []string{ScopeService}},
+		"qos/ratelimit/router": {Title: "Rate limit", Description: "Limits the requests per second.", Scopes: []string{ScopeEndpoint, ScopeService}},
+		"qos/circuit-breaker":  {Title: "Circuit breaker", Scopes: []string{ScopeBackend}},
+	}, namespaces)
+}
+
+const testConfig = `{
+  "version": 3,
+  "host": ["http://default"],
+  "endpoints": [
+    {
+      "endpoint": "/a",
+      "extra_config": {
+        ""
+      },
+      "backend": [
+        {"url_pattern": "/a", "host": ["http://a"]},
+        {"url_pattern": "/b"}
+      ]
+    }
+  ]
+}`
+
+func TestServer(t *testing.T) {
+	namespaces, err := Namespaces([]byte(testSchema))
+	require.NoError(t, err)
+	s := &Server{
+		Namespaces: namespaces,
+		Diagnose: func(path string, _ []byte) []Problem {
+			return []Problem{
+				{Position: source.Position{File: path, Line: 6, Column: 19}, Severity: SeverityWarning, Code: "1.1.1", Source: "test", Message: "local"},
+				{Position: source.Position{File: "partial.json", Line: 2, Column: 3}, Severity: SeverityError, Source: "test", Message: "remote"},
+			}
+		},
+	}
+
+	uri := "file:///tmp/krakend.json"
+	var in bytes.Buffer
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q,"text":%q}}}`, uri, testConfig),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"textDocument/completion","params":{"textDocument":{"uri":%q},"position":{"line":7,"character":9}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"textDocument/hover","params":{"textDocument":{"uri":%q},"position":{"line":7,"character":9}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":%q},"contentChanges":[{"text":%q}]}}`, uri, strings.Replace(testConfig, `""`, `"qos/ratelimit/router": {}`, 1)),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":4,"method":"textDocument/definition","params":{"textDocument":{"uri":%q},"position":{"line":10,"character":12}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":5,"method":"textDocument/definition","params":{"textDocument":{"uri":%q},"position":{"line":11,"character":12}}}`, uri),
+		`{"jsonrpc":"2.0","id":6,"method":"unknown"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	}
+	for _, r := range requests {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(r), r)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, s.Serve(&in, &out))
+
+	var messages []map[string]interface{}
+	reader := bufio.NewReader(&out)
+	for {
+		msg, err := readRaw(reader)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		messages = append(messages, msg)
+	}
+	require.Len(t, messages, 9)
+
+	diagnostics := messages[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	require.Equal(t, "textDocument/publishDiagnostics", messages[1]["method"])
+	require.Len(t, diagnostics, 2)
+	require.Equal(t, map[string]interface{}{
+		"start": map[string]interface{}{"line": 5.0, "character": 18.0},
+		"end":   map[string]interface{}{"line": 5.0, "character": 22.0},
+	}, diagnostics[0].(map[string]interface{})["range"])
+	require.Equal(t, "1.1.1", diagnostics[0].(map[string]interface{})["code"])
+	require.Equal(t, "partial.json:2:3: remote", diagnostics[1].(map[string]interface{})["message"])
+
+	items := messages[2]["result"].([]interface{})
+	require.Len(t, items, 1)
+	require.Equal(t, "qos/ratelimit/router", items[0].(map[string]interface{})["label"])
+	require.Equal(t, "qos/ratelimit/router", items[0].(map[string]interface{})["insertText"])
+
+	require.Nil(t, messages[3]["result"])
+
+	hostRange := func(m map[string]interface{}) interface{} {
+		locations := m["result"].([]interface{})
+		require.Len(t, locations, 1)
+		return locations[0].(map[string]interface{})["range"].(map[string]interface{})["start"]
+	}
+	require.Equal(t, "textDocument/publishDiagnostics", messages[4]["method"])
+	require.Equal(t, map[string]interface{}{"line": 10.0, "character": 30.0}, hostRange(messages[5]))
+	require.Equal(t, map[string]interface{}{"line": 2.0, "character": 2.0}, hostRange(messages[6]))
+
+	require.Equal(t, float64(codeMethodNotFound), messages[7]["error"].(map[string]interface{})["code"])
+	require.Contains(t, messages[8], "result")
+	require.Nil(t, messages[8]["result"])
+}
+
+func TestServer_hover(t *testing.T) {
+	namespaces, err := Namespaces([]byte(testSchema))
+	require.NoError(t, err)
+	s := &Server{Namespaces: namespaces, docs: map[string]*document{}}
+
+	d := newDocument("file:///tmp/krakend.yaml", []byte("endpoints:\n  - endpoint: /a\n    extra_config:\n      qos/ratelimit/router:\n        max_rate: 1\n      \n"))
+	h := s.hover(d, Position{Line: 3, Character: 10})
+	require.NotNil(t, h)
+	require.Equal(t, "**qos/ratelimit/router**\n\nRate limit\n\nLimits the requests per second.\n\nScope: endpoint, service", h.Contents.Value)
+	require.Equal(t, &Range{Start: Position{Line: 3, Character: 6}, End: Position{Line: 3, Character: 26}}, h.Range)
+
+	items := s.completion(d, Position{Line: 5, Character: 6})
+	require.Len(t, items, 1)
+	require.Equal(t, "qos/ratelimit/router:", items[0].InsertText)
+}
+
+func TestServer_exitWithoutShutdown(t *testing.T) {
+	r := `{"jsonrpc":"2.0","method":"exit"}`
+	in := bytes.NewBufferString(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(r), r))
+	require.ErrorIs(t, (&Server{}).Serve(in, io.Discard), ErrExitWithoutShutdown)
+}
+
+func readRaw(r *bufio.Reader) (map[string]interface{}, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, err
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	return m, json.Unmarshal(body, &m)
+}
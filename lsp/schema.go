@@ -0,0 +1,208 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Scopes of the extra_config namespaces
+const (
+	ScopeService  = "service"
+	ScopeEndpoint = "endpoint"
+	ScopeBackend  = "backend"
+	ScopeAgent    = "async_agent"
+)
+
+// Namespace documents an extra_config namespace
+type Namespace struct {
+	Title       string
+	Description string
+	// Scopes are the elements accepting the namespace
+	Scopes []string
+}
+
+// Markdown renders the documentation of the namespace
+func (n Namespace) Markdown(name string) string {
+	parts := []string{"**" + name + "**"}
+	if n.Title != "" {
+		parts = append(parts, n.Title)
+	}
+	if n.Description != "" {
+		parts = append(parts, n.Description)
+	}
+	if len(n.Scopes) > 0 {
+		parts = append(parts, "Scope: "+strings.Join(n.Scopes, ", "))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Namespaces returns the extra_config namespaces documented in the JSON schema of the
+// configuration. The schema can reference its parts with JSON pointers or with the
+// $id of the bundled schemas.
+func Namespaces(schema []byte) (map[string]Namespace, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, err
+	}
+	w := &schemaWalker{root: root, ids: map[string]map[string]interface{}{}, visited: map[string]bool{}, res: map[string]Namespace{}}
+	w.index(root)
+	w.walk(root, ScopeService)
+	for name, ns := range w.res {
+		sort.Strings(ns.Scopes)
+		w.res[name] = ns
+	}
+	return w.res, nil
+}
+
+type schemaWalker struct {
+	root    interface{}
+	ids     map[string]map[string]interface{}
+	visited map[string]bool
+	res     map[string]Namespace
+}
+
+// index collects the schemas declaring an $id
+func (w *schemaWalker) index(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if id, ok := t["$id"].(string); ok {
+			w.ids[id] = t
+		}
+		for _, c := range t {
+			w.index(c)
+		}
+	case []interface{}:
+		for _, c := range t {
+			w.index(c)
+		}
+	}
+}
+
+// resolve follows the $ref of the schema, if any
+func (w *schemaWalker) resolve(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	for i := 0; obj != nil && i < 10; i++ {
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj
+		}
+		obj = w.lookup(ref)
+	}
+	return obj
+}
+
+func (w *schemaWalker) lookup(ref string) map[string]interface{} {
+	base, fragment, _ := strings.Cut(ref, "#")
+	var doc interface{} = w.root
+	if base != "" {
+		s, ok := w.ids[base]
+		if !ok {
+			// relative references of the bundled schemas, resolved against their $id
+			ids := make([]string, 0, len(w.ids))
+			for id := range w.ids {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				if strings.HasSuffix(id, "/"+strings.TrimPrefix(base, "./")) {
+					s = w.ids[id]
+					break
+				}
+			}
+		}
+		if s == nil {
+			return nil
+		}
+		doc = s
+	}
+	for _, p := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		if p == "" {
+			continue
+		}
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		doc = obj[strings.NewReplacer("~1", "/", "~0", "~").Replace(p)]
+	}
+	obj, _ := doc.(map[string]interface{})
+	return obj
+}
+
+// walk looks for the extra_config properties of the schema, tracking the scope of the
+// elements they belong to
+func (w *schemaWalker) walk(v interface{}, scope string) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	// the references can be recursive, so every schema is walked once per scope
+	key := fmt.Sprintf("%s|%x", scope, reflect.ValueOf(obj).Pointer())
+	if w.visited[key] {
+		return
+	}
+	w.visited[key] = true
+
+	if ref, ok := obj["$ref"].(string); ok {
+		w.walk(w.lookup(ref), scope)
+	}
+	if props, ok := obj["properties"].(map[string]interface{}); ok {
+		for name, p := range props {
+			switch name {
+			case "extra_config":
+				w.collect(p, scope)
+			case "endpoints":
+				w.walk(p, ScopeEndpoint)
+			case "backend":
+				w.walk(p, ScopeBackend)
+			case "async_agent":
+				w.walk(p, ScopeAgent)
+			default:
+				w.walk(p, scope)
+			}
+		}
+	}
+	for _, k := range []string{"items", "additionalProperties", "if", "then", "else"} {
+		w.walk(obj[k], scope)
+	}
+	for _, k := range []string{"allOf", "anyOf", "oneOf"} {
+		if list, ok := obj[k].([]interface{}); ok {
+			for _, s := range list {
+				w.walk(s, scope)
+			}
+		}
+	}
+}
+
+// collect adds the namespaces of the extra_config schema
+func (w *schemaWalker) collect(v interface{}, scope string) {
+	obj := w.resolve(v)
+	if obj == nil {
+		return
+	}
+	props, _ := obj["properties"].(map[string]interface{})
+	for name, p := range props {
+		ns := w.res[name]
+		for _, s := range []map[string]interface{}{asObject(p), w.resolve(p)} {
+			if ns.Title == "" {
+				ns.Title, _ = s["title"].(string)
+			}
+			if ns.Description == "" {
+				ns.Description, _ = s["description"].(string)
+			}
+		}
+		if !slices.Contains(ns.Scopes, scope) {
+			ns.Scopes = append(ns.Scopes, scope)
+		}
+		w.res[name] = ns
+	}
+}
+
+func asObject(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	return obj
+}
@@ -0,0 +1,279 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+)
+
+// completionItemKindProperty is the kind of the completion items of the namespaces
+const completionItemKindProperty = 10
+
+// Problem is a problem found in a configuration file, located in its source
+type Problem struct {
+	source.Position
+	Severity int
+	Code     string
+	Source   string
+	Message  string
+}
+
+// Server is a language server for the KrakenD configuration files
+type Server struct {
+	// Diagnose returns the problems of the configuration file with the content. It is
+	// called every time the document changes.
+	Diagnose func(path string, content []byte) []Problem
+	// Namespaces document the extra_config namespaces for the completion and the hover
+	Namespaces map[string]Namespace
+	// Log receives the errors that cannot be reported to the client
+	Log io.Writer
+
+	docs     map[string]*document
+	out      io.Writer
+	shutdown bool
+}
+
+// ErrExitWithoutShutdown is returned by Serve when the client exits without asking
+// the server to shut down first
+var ErrExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Serve handles the messages of the client until it exits or closes the input
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.docs = map[string]*document{}
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		m, err := readMessage(r)
+		if err != nil {
+			var rerr *responseError
+			if errors.As(err, &rerr) {
+				if err := writeResponse(out, nil, nil, rerr); err != nil {
+					return err
+				}
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if m.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(m); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(m message) error {
+	result, rerr := s.dispatch(m)
+	if m.ID == nil {
+		if rerr != nil {
+			s.logf("%s: %s\n", m.Method, rerr.Message)
+		}
+		return nil
+	}
+	return writeResponse(s.out, m.ID, result, rerr)
+}
+
+// dispatch runs the handler of the method, returning the result of the requests
+func (s *Server) dispatch(m message) (interface{}, *responseError) { // skipcq: GO-R1005
+	switch m.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   map[string]interface{}{"openClose": true, "change": 1, "save": map[string]bool{"includeText": true}},
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{`"`}},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "krakend"},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		return nil, s.update(p.TextDocument.URI, []byte(p.TextDocument.Text))
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		// the server asks for the full content of the document on every change
+		if len(p.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.update(p.TextDocument.URI, []byte(p.ContentChanges[len(p.ContentChanges)-1].Text))
+	case "textDocument/didSave":
+		var p didSaveParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		if p.Text != nil {
+			return nil, s.update(p.TextDocument.URI, []byte(*p.Text))
+		}
+		if d, ok := s.docs[p.TextDocument.URI]; ok {
+			return nil, s.update(d.uri, d.text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var p textDocumentParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.docs, p.TextDocument.URI)
+		return nil, s.publish(p.TextDocument.URI, []Diagnostic{})
+
+	case "textDocument/completion":
+		d, p, rerr := s.position(m)
+		if rerr != nil {
+			return nil, rerr
+		}
+		return s.completion(d, p), nil
+	case "textDocument/hover":
+		d, p, rerr := s.position(m)
+		if rerr != nil {
+			return nil, rerr
+		}
+		return s.hover(d, p), nil
+	case "textDocument/definition":
+		d, p, rerr := s.position(m)
+		if rerr != nil {
+			return nil, rerr
+		}
+		return s.definition(d, p), nil
+
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + m.Method}
+	}
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+func (s *Server) position(m message) (*document, Position, *responseError) {
+	var p textDocumentPositionParams
+	if err := json.Unmarshal(m.Params, &p); err != nil {
+		return nil, Position{}, invalidParams(err)
+	}
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return nil, Position{}, &responseError{Code: codeInvalidParams, Message: "unknown document " + p.TextDocument.URI}
+	}
+	return d, p.Position, nil
+}
+
+// update stores the content of the document and publishes its diagnostics
+func (s *Server) update(uri string, text []byte) *responseError {
+	d := newDocument(uri, text)
+	s.docs[uri] = d
+
+	diagnostics := []Diagnostic{}
+	if s.Diagnose != nil {
+		for _, p := range s.Diagnose(d.path, text) {
+			diagnostics = append(diagnostics, d.diagnostic(p))
+		}
+	}
+	return s.publish(uri, diagnostics)
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) *responseError {
+	n := notification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics}}
+	if err := writeMessage(s.out, n); err != nil {
+		return &responseError{Message: err.Error()}
+	}
+	return nil
+}
+
+// diagnostic locates the problem in the document. The problems without a line or
+// found in other files, like the partials of the flexible configuration, are reported
+// at the beginning of the document.
+func (d *document) diagnostic(p Problem) Diagnostic {
+	res := Diagnostic{Severity: p.Severity, Code: p.Code, Source: p.Source, Message: p.Message}
+	switch {
+	case p.Line > 0 && p.File == d.path:
+		res.Range = d.tokenRange(p.Position)
+	case p.File != "" && p.File != d.path:
+		res.Message = p.Position.String() + ": " + p.Message
+	}
+	return res
+}
+
+// completion suggests the namespaces accepted by the extra_config at the position
+func (s *Server) completion(d *document, p Position) []CompletionItem {
+	items := []CompletionItem{}
+	scope, quoted, ok := d.extraConfigScope(p)
+	if !ok {
+		return items
+	}
+	names := make([]string, 0, len(s.Namespaces))
+	for name, ns := range s.Namespaces {
+		if len(ns.Scopes) == 0 || slices.Contains(ns.Scopes, scope) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ns := s.Namespaces[name]
+		// inside quotes only the name is missing
+		insert := name
+		if !quoted {
+			insert = name + ":"
+			if d.format == source.JSON {
+				insert = fmt.Sprintf("%q: {}", name)
+			}
+		}
+		items = append(items, CompletionItem{
+			Label:         name,
+			Kind:          completionItemKindProperty,
+			Detail:        ns.Title,
+			Documentation: &MarkupContent{Kind: "markdown", Value: ns.Markdown(name)},
+			InsertText:    insert,
+		})
+	}
+	return items
+}
+
+// hover documents the namespace at the position
+func (s *Server) hover(d *document, p Position) *Hover {
+	name, r := d.token(p)
+	ns, ok := s.Namespaces[name]
+	if !ok {
+		return nil
+	}
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: ns.Markdown(name)}, Range: &r}
+}
+
+// definition goes from the backend at the position to its hosts
+func (s *Server) definition(d *document, p Position) []Location {
+	pos, ok := d.backendHost(p)
+	if !ok {
+		return []Location{}
+	}
+	return []Location{{URI: d.uri, Range: d.tokenRange(pos)}}
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.Log != nil {
+		fmt.Fprintf(s.Log, format, args...)
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krakend/krakend-cobra/v2/lsp"
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lspDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	rulesPath := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(`rules:
+  - id: ORG-001
+    severity: HIGH
+    message: the endpoints must be rate limited
+    scope: endpoint
+    assert: [{path: extra_config.qos/ratelimit/router.max_rate, op: exists}]
+`), 0o600))
+	rules, err := policy.Load(rulesPath)
+	require.NoError(t, err)
+	opts := auditOptions{rules: rules, severities: []string{"HIGH"}}
+
+	rawSchema, err := jsonschema.UnmarshalJSON(strings.NewReader(`{"properties": {"endpoints": {"items": {"properties": {"method": {"enum": ["GET", "POST"]}}}}}}`))
+	require.NoError(t, err)
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource("schema.json", rawSchema))
+	sch, err := compiler.Compile("schema.json")
+	require.NoError(t, err)
+
+	defer func(f func(config.ServiceConfig) []error) { CustomValidationFunc = f }(CustomValidationFunc)
+	CustomValidationFunc = func(config.ServiceConfig) []error {
+		return []error{testConfigError{"endpoints", "0", "endpoint"}}
+	}
+
+	path := filepath.Join(dir, "krakend.json")
+	problems := lspDiagnostics(path, []byte(`{
+  "version": 3,
+  "endpoints": [
+    {
+      "endpoint": "/a",
+      "method": "PUT",
+      "backend": [{"url_pattern": "/a", "host": ["http://a"]}]
+    }
+  ]
+}`), sch, opts)
+
+	byCode := map[string]lsp.Problem{}
+	for _, p := range problems {
+		byCode[p.Source+" "+p.Code] = p
+	}
+	require.Equal(t, source.Position{File: path, Line: 6, Column: 7}, byCode[lspSourceLint+" "].Position)
+	require.Equal(t, lsp.SeverityError, byCode[lspSourceLint+" "].Severity)
+	require.Equal(t, lsp.Problem{Position: source.Position{File: path, Line: 5, Column: 7}, Severity: lsp.SeverityError, Source: lspSourceCheck, Message: "boom"}, byCode[lspSourceCheck+" "])
+	require.Equal(t, source.Position{File: path, Line: 4, Column: 5}, byCode[lspSourceAudit+" ORG-001"].Position)
+	require.Equal(t, lsp.SeverityWarning, byCode[lspSourceAudit+" ORG-001"].Severity)
+
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected source.Position
+	}{
+		{name: "krakend.json", content: "{\n  \"version\": 3,\n  \"endpoints\": [}\n}", expected: source.Position{Line: 3, Column: 18}},
+		{name: "krakend.yaml", content: "version: 3\nendpoints:\n  - endpoint: /a\n   method: GET\n", expected: source.Position{Line: 2, Column: 1}},
+		{name: "krakend.toml", content: "version = 3\nname = \n", expected: source.Position{Line: 3, Column: 1}},
+	} {
+		path := filepath.Join(dir, tc.name)
+		tc.expected.File = path
+		problems := lspDiagnostics(path, []byte(tc.content), nil, opts)
+		require.Len(t, problems, 1, tc.name)
+		require.Equal(t, tc.expected, problems[0].Position, tc.name)
+		require.Equal(t, lspSourceCheck, problems[0].Source, tc.name)
+	}
+}
+
+func Test_lspFunc_stdin(t *testing.T) {
+	var in, out bytes.Buffer
+	for _, r := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(r), r)
+	}
+	cmd := &cobra.Command{}
+	cmd.SetIn(&in)
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+
+	lspFunc(cmd, nil)
+	require.Contains(t, out.String(), `"id":1`)
+}
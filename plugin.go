@@ -4,34 +4,53 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/krakend/krakend-cobra/v2/plugin"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
 	"golang.org/x/mod/modfile"
 )
 
-// indirectRequires returns the indirect dependencies of the go.sum file.
-func indirectRequires(goSum string) (map[string]struct{}, error) {
-	dir := filepath.Dir(goSum)
-	filename := filepath.Join(dir, "go.mod")
+// goModPath returns the path of the go.mod file sitting next to the go.sum file.
+func goModPath(goSum string) string {
+	return filepath.Join(filepath.Dir(goSum), "go.mod")
+}
+
+// parseGoMod reads and parses the go.mod file sitting next to the go.sum file.
+func parseGoMod(goSum string) (*modfile.File, []byte, error) {
+	filename := goModPath(goSum)
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		return nil, fmt.Errorf("reading go.mod: %w", err)
+		return nil, nil, fmt.Errorf("reading go.mod: %w", err)
 	}
 
 	f, err := modfile.Parse(filename, data, nil)
 	if err != nil {
-		return nil, fmt.Errorf("parsing go.mod: %w", err)
+		return nil, nil, fmt.Errorf("parsing go.mod: %w", err)
 	}
+	return f, data, nil
+}
+
+// indirectRequires returns the indirect dependencies of the go.sum file.
+func indirectRequires(goSum string) (map[string]struct{}, error) {
+	f, _, err := parseGoMod(goSum)
+	if err != nil {
+		return nil, err
+	}
+
+	return indirectModules(f), nil
+}
 
+// indirectModules returns the indirect dependencies declared in the go.mod file.
+func indirectModules(f *modfile.File) map[string]struct{} {
 	indirects := map[string]struct{}{}
 	for _, r := range f.Require {
 		if r.Indirect {
 			indirects[r.Mod.Path] = struct{}{}
 		}
 	}
-
-	return indirects, nil
+	return indirects
 }
 
 // getBuildInfo returns the dependencies of the binary calling it.
@@ -40,6 +59,26 @@ func indirectRequires(goSum string) (map[string]struct{}, error) {
 // https://github.com/golang/go/issues/68045
 var localDescriber = plugin.Local
 
+// hostDescriber returns the descriptor to check the plugin against: the one
+// stored in the --host file or, by default, the one of the running binary.
+func hostDescriber() (plugin.Descriptor, error) {
+	if hostDescriptor == "" {
+		return localDescriber(), nil
+	}
+
+	f, err := os.Open(hostDescriptor)
+	if err != nil {
+		return plugin.Descriptor{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	desc, err := plugin.Load(f)
+	if err != nil {
+		return plugin.Descriptor{}, fmt.Errorf("parsing the host descriptor: %w", err)
+	}
+	return desc, nil
+}
+
 func pluginFunc(cmd *cobra.Command, args []string) {
 	if err := pluginFuncErr(cmd, args); err != nil {
 		cmd.Println(err)
@@ -47,32 +86,65 @@ func pluginFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
-func pluginFuncErr(cmd *cobra.Command, _ []string) error {
+// pluginDescriber returns the descriptor of the plugin: the one embedded in the
+// --plugin binary or, by default, the one built from the go.sum file with the received
+// go and libc versions.
+func pluginDescriber(goVer, libc string) (plugin.Descriptor, error) {
+	if pluginBinary != "" {
+		return plugin.DescribeBinary(pluginBinary, libc)
+	}
+
 	f, err := os.Open(goSum)
 	if err != nil {
-		return err
+		return plugin.Descriptor{}, err
 	}
 
 	defer func() { _ = f.Close() }() // Workaround false positive for GO-S2307.
 
-	desc, err := plugin.Describe(f, goVersion, libcVersion)
+	return plugin.Describe(f, goVer, libc)
+}
+
+func pluginFuncErr(cmd *cobra.Command, _ []string) error {
+	host, err := hostDescriber()
+	if err != nil {
+		return err
+	}
+
+	// the defaults of --go and --libc are the ones of the running binary, so the
+	// plugins checked against a --host descriptor default to the ones of the host.
+	// The libc of a --plugin binary is read from it.
+	goV, libc := goVersion, libcVersion
+	if hostDescriptor != "" {
+		if !cmd.Flags().Changed("go") {
+			goV = host.Go
+		}
+		if !cmd.Flags().Changed("libc") && pluginBinary == "" {
+			libc = host.Libc
+		}
+	}
+
+	desc, err := pluginDescriber(goV, libc)
 	if err != nil {
 		return err
 	}
 
-	diffs := localDescriber().Compare(desc)
+	diffs := host.Compare(desc)
 	if len(diffs) == 0 {
 		cmd.Println("No incompatibilities found!")
 		return nil
 	}
 
+	if fixEnabled {
+		return fixGoMod(cmd, diffs)
+	}
+
 	if gogetEnabled {
 		indirects, err := indirectRequires(goSum)
 		if err != nil {
 			return err
 		}
 		for _, diff := range diffs {
-			if diff.Name != "go" && diff.Name != "libc" {
+			if diff.Kind == plugin.KindDependency {
 				if _, ok := indirects[diff.Name]; ok {
 					cmd.Printf("go mod edit --replace %s=%s@%s\n", diff.Name, diff.Name, diff.Expected)
 				} else {
@@ -81,17 +153,147 @@ func pluginFuncErr(cmd *cobra.Command, _ []string) error {
 				continue
 			}
 
-			cmd.Println(diff.Name)
-			cmd.Println("\thave:", diff.Have)
-			cmd.Println("\twant:", diff.Expected)
+			printDiff(cmd, diff)
 		}
 	} else {
 		for _, diff := range diffs {
-			cmd.Println(diff.Name)
-			cmd.Println("\thave:", diff.Have)
-			cmd.Println("\twant:", diff.Expected)
+			printDiff(cmd, diff)
 		}
 	}
 
 	return fmt.Errorf("%d incompatibilities found", len(diffs))
 }
+
+func printDiff(cmd *cobra.Command, diff plugin.Diff) {
+	cmd.Println(diff.Name)
+	cmd.Println("\thave:", diff.Have)
+	cmd.Println("\twant:", diff.Expected)
+	if diff.Reason != "" {
+		cmd.Println("\twhy:", diff.Reason)
+	}
+}
+
+// fixGoMod rewrites the go.mod file of the plugin so it matches the dependencies
+// of the host. The changes are printed as a unified diff before being written
+// and nothing is written when the dry-run mode is enabled.
+func fixGoMod(cmd *cobra.Command, diffs []plugin.Diff) error {
+	f, original, err := parseGoMod(goSum)
+	if err != nil {
+		return err
+	}
+
+	indirects := indirectModules(f)
+	required := map[string]string{}
+	for _, r := range f.Require {
+		required[r.Mod.Path] = r.Mod.Version
+	}
+
+	var unfixable []plugin.Diff
+	for _, diff := range diffs {
+		switch diff.Kind {
+		case plugin.KindLibc, plugin.KindBuild:
+			unfixable = append(unfixable, diff)
+			continue
+		case plugin.KindGo:
+			err = f.AddGoStmt(diff.Expected)
+			if err == nil {
+				err = f.AddToolchainStmt("go" + diff.Expected)
+			}
+		default:
+			// when the go.mod already requires the host version, another dependency
+			// selects a higher one, so only a replace pins it
+			if _, ok := indirects[diff.Name]; ok || required[diff.Name] == diff.Expected {
+				err = f.AddReplace(diff.Name, "", diff.Name, diff.Expected)
+			} else {
+				err = f.AddRequire(diff.Name, diff.Expected)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("fixing %s: %w", diff.Name, err)
+		}
+	}
+
+	f.Cleanup()
+	fixed, err := f.Format()
+	if err != nil {
+		return fmt.Errorf("formatting go.mod: %w", err)
+	}
+
+	filename := goModPath(goSum)
+	patch, err := unifiedDiff(filename, original, fixed)
+	if err != nil {
+		return fmt.Errorf("diffing go.mod: %w", err)
+	}
+	fmt.Fprint(cmd.OutOrStdout(), patch)
+
+	if !fixDryRun && patch != "" {
+		if err := os.WriteFile(filename, fixed, 0o644); err != nil { // skipcq: GSC-G306
+			return fmt.Errorf("writing go.mod: %w", err)
+		}
+		cmd.Println("go.mod updated. Run 'go mod tidy' to refresh the go.sum file")
+	}
+
+	for _, diff := range unfixable {
+		printDiff(cmd, diff)
+	}
+
+	if fixDryRun {
+		return fmt.Errorf("%d incompatibilities found", len(diffs))
+	}
+	if len(unfixable) > 0 {
+		return fmt.Errorf("%d incompatibilities can not be fixed automatically", len(unfixable))
+	}
+	return nil
+}
+
+// unifiedDiff returns the changes between the two versions of the file in the unified format
+func unifiedDiff(filename string, a, b []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: filename,
+		ToFile:   filename,
+		Context:  3,
+	})
+}
+
+func splitLines(b []byte) []string {
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func pluginInitFunc(cmd *cobra.Command, args []string) {
+	dst := "."
+	if len(args) > 0 {
+		dst = args[0]
+	}
+	name := scaffoldName
+	if name == "" {
+		abs, err := filepath.Abs(dst)
+		if err != nil {
+			cmd.Println(errorMsg("ERROR resolving the plugin folder:") + fmt.Sprintf("\t%s\n", err.Error()))
+			os.Exit(1) // skipcq: RVV-A0003
+			return
+		}
+		name = filepath.Base(abs)
+	}
+
+	files, err := plugin.Scaffold(dst, plugin.ScaffoldConfig{
+		Type:   scaffoldType,
+		Name:   name,
+		Module: scaffoldModule,
+	}, localDescriber())
+	if err != nil {
+		cmd.Println(errorMsg("ERROR generating the plugin:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	for _, f := range files {
+		cmd.Println("created", f)
+	}
+	cmd.Printf("The %s plugin %s is ready. Run 'make' in %s to test and build it\n", scaffoldType, name, dst)
+}
@@ -2,6 +2,9 @@ package plugin
 
 import (
 	"bufio"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
 	"io"
 	"runtime/debug"
 	"sort"
@@ -13,25 +16,76 @@ import (
 
 // Descriptor lists all the deps and versions required by a binary/plugin
 type Descriptor struct {
-	Go   string
-	Libc string
-	Deps map[string]string
+	KrakenD  string            `json:"krakend,omitempty"`
+	Go       string            `json:"go"`
+	Libc     string            `json:"libc"`
+	Deps     map[string]string `json:"deps"`
+	Revision string            `json:"vcs_revision,omitempty"`
+	Settings map[string]string `json:"build_settings,omitempty"`
 }
 
 // Local returns a descriptor for the binary calling it
 func Local() Descriptor {
+	deps, settings := getBuildInfo()
 	return Descriptor{
-		Go:   core.GoVersion,
-		Libc: core.GlibcVersion,
-		Deps: getBuildInfo(),
+		KrakenD:  core.KrakendVersion,
+		Go:       core.GoVersion,
+		Libc:     core.GlibcVersion,
+		Deps:     deps,
+		Revision: settings["vcs.revision"],
+		Settings: settings,
 	}
 }
 
+// Load decodes a descriptor previously serialized as JSON
+func Load(r io.Reader) (Descriptor, error) {
+	var d Descriptor
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return Descriptor{}, err
+	}
+	if d.Deps == nil {
+		d.Deps = map[string]string{}
+	}
+	return d, nil
+}
+
+// Kinds of incompatibilities between descriptors
+const (
+	KindDependency = "dependency"
+	KindGo         = "go"
+	KindLibc       = "libc"
+	KindBuild      = "build"
+)
+
 // Diff points an incompatibility between descriptors
 type Diff struct {
 	Name     string
 	Expected string
 	Have     string
+	Kind     string
+	// Reason explains why the difference prevents the plugin from being loaded
+	Reason string
+}
+
+// buildSetting is a setting of the build info that must match between
+// the binary and the plugin
+type buildSetting struct {
+	Key     string
+	Default string
+	Reason  string
+}
+
+var buildSettings = []buildSetting{
+	{Key: "GOOS", Reason: "plugins must be built for the same operating system as the binary"},
+	{Key: "GOARCH", Reason: "plugins must be built for the same architecture as the binary"},
+	{Key: "GOAMD64", Reason: "the microarchitecture level changes the code of the packages shared with the binary"},
+	{Key: "GOARM", Reason: "the microarchitecture level changes the code of the packages shared with the binary"},
+	{Key: "GOARM64", Reason: "the microarchitecture level changes the code of the packages shared with the binary"},
+	{Key: "GO386", Reason: "the microarchitecture level changes the code of the packages shared with the binary"},
+	{Key: "CGO_ENABLED", Reason: "plugins are loaded with cgo and the packages shared with the binary are compiled differently without it"},
+	{Key: "-trimpath", Default: "false", Reason: "the paths recorded in the shared packages must match or the runtime reports them as built with a different version"},
+	{Key: "-tags", Reason: "build tags change the content of the packages shared with the binary"},
+	{Key: "-race", Default: "false", Reason: "the race detector instruments the packages shared with the binary"},
 }
 
 // Compare generates a list of diffs (incompatibility) between two descriptors
@@ -44,23 +98,41 @@ func (d Descriptor) Compare(other Descriptor) []Diff {
 			continue
 		}
 		if v != expectedVersion {
-			diffs = append(diffs, Diff{Name: pkgName, Expected: expectedVersion, Have: v})
+			diffs = append(diffs, Diff{Name: pkgName, Expected: expectedVersion, Have: v, Kind: KindDependency})
 		}
 	}
 
 	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
 
+	// build settings are only known when both descriptors come from a binary
+	if d.Settings != nil && other.Settings != nil {
+		for i := len(buildSettings) - 1; i >= 0; i-- {
+			s := buildSettings[i]
+			expected, have := d.setting(s), other.setting(s)
+			if expected != have {
+				diffs = prependDiff(diffs, Diff{Name: s.Key, Expected: expected, Have: have, Kind: KindBuild, Reason: s.Reason})
+			}
+		}
+	}
+
 	if d.Go != other.Go {
-		diffs = prependDiff(diffs, Diff{Name: "go", Expected: d.Go, Have: other.Go})
+		diffs = prependDiff(diffs, Diff{Name: "go", Expected: d.Go, Have: other.Go, Kind: KindGo})
 	}
 
-	if d.Libc != other.Libc {
-		diffs = prependDiff(diffs, Diff{Name: "libc", Expected: d.Libc, Have: other.Libc})
+	if diff, ok := compareLibc(d.Libc, other.Libc); ok {
+		diffs = prependDiff(diffs, diff)
 	}
 
 	return diffs
 }
 
+func (d Descriptor) setting(s buildSetting) string {
+	if v, ok := d.Settings[s.Key]; ok {
+		return v
+	}
+	return s.Default
+}
+
 func prependDiff(diffs []Diff, diff Diff) []Diff {
 	tmp := make([]Diff, len(diffs)+1)
 	copy(tmp[1:], diffs)
@@ -95,6 +167,30 @@ func Describe(r io.Reader, goVersion, libcVersion string) (Descriptor, error) {
 	}, nil
 }
 
+// DescribeBinary reads the build info embedded in a plugin (or any go binary)
+// and returns a descriptor including its build settings. When no libc version
+// is received, the one required by the binary is used.
+func DescribeBinary(path, libcVersion string) (Descriptor, error) {
+	bi, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("reading the build info: %w", err)
+	}
+	if libcVersion == "" {
+		libcVersion, err = RequiredLibc(path)
+		if err != nil {
+			return Descriptor{}, err
+		}
+	}
+	deps, settings := parseBuildInfo(bi)
+	return Descriptor{
+		Go:       strings.TrimPrefix(bi.GoVersion, "go"),
+		Libc:     libcVersion,
+		Deps:     deps,
+		Revision: settings["vcs.revision"],
+		Settings: settings,
+	}, nil
+}
+
 func cleanVersion(v string) string {
 	if l := len(v); l > 7 && v[l-7:] == "/go.mod" {
 		return v[:l-7]
@@ -112,15 +208,28 @@ func parseSumFile(r io.Reader) ([]string, error) {
 	return lines, scanner.Err()
 }
 
-func getBuildInfo() map[string]string {
-	res := map[string]string{}
+func getBuildInfo() (map[string]string, map[string]string) {
 	bi, ok := debug.ReadBuildInfo()
 	if !ok {
-		return res
+		return map[string]string{}, nil
 	}
+	return parseBuildInfo(bi)
+}
 
+// parseBuildInfo returns the versions of the dependencies, following their replace
+// directives, and the build settings. The modules replaced by a local folder keep the
+// version of the requirement, as they have none.
+func parseBuildInfo(bi *debug.BuildInfo) (map[string]string, map[string]string) {
+	deps := map[string]string{}
+	settings := map[string]string{}
 	for _, dep := range bi.Deps {
-		res[dep.Path] = dep.Version
+		deps[dep.Path] = dep.Version
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			deps[dep.Path] = dep.Replace.Version
+		}
+	}
+	for _, s := range bi.Settings {
+		settings[s.Key] = s.Value
 	}
-	return res
+	return deps, settings
 }
@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescriptor_Compare_buildSettings(t *testing.T) {
+	host := Descriptor{
+		Go:   "1.25.3",
+		Deps: map[string]string{"golang.org/x/mod": "v0.35.0"},
+		Settings: map[string]string{
+			"GOOS":        "linux",
+			"GOARCH":      "amd64",
+			"GOAMD64":     "v1",
+			"CGO_ENABLED": "1",
+			"-trimpath":   "true",
+		},
+	}
+
+	tests := map[string]struct {
+		settings map[string]string
+		expected []string
+	}{
+		"unknown settings": {},
+		"matching": {
+			settings: map[string]string{
+				"GOOS":        "linux",
+				"GOARCH":      "amd64",
+				"GOAMD64":     "v1",
+				"CGO_ENABLED": "1",
+				"-trimpath":   "true",
+				"-buildmode":  "plugin",
+			},
+		},
+		"mismatches": {
+			settings: map[string]string{
+				"GOOS":        "linux",
+				"GOARCH":      "amd64",
+				"GOAMD64":     "v3",
+				"CGO_ENABLED": "1",
+				"-tags":       "netgo",
+			},
+			expected: []string{"GOAMD64", "-trimpath", "-tags"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			diffs := host.Compare(Descriptor{Go: host.Go, Deps: host.Deps, Settings: tc.settings})
+			names := make([]string, len(diffs))
+			for i, diff := range diffs {
+				require.Equal(t, KindBuild, diff.Kind)
+				require.NotEmpty(t, diff.Reason)
+				names[i] = diff.Name
+			}
+			if len(tc.expected) == 0 {
+				require.Empty(t, diffs)
+				return
+			}
+			require.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestDescriptor_Compare_libc(t *testing.T) {
+	tests := map[string]struct {
+		host, plugin string
+		reason       string
+	}{
+		"undefined host":       {host: "undefined", plugin: "GLIBC-2.36"},
+		"unknown plugin":       {host: "GLIBC-2.31_buildpack", plugin: ""},
+		"same version":         {host: "GLIBC-2.31_buildpack", plugin: "GLIBC-2.31"},
+		"older plugin":         {host: "GLIBC-2.36_buildpack", plugin: "GLIBC_2.2.5"},
+		"newer plugin":         {host: "GLIBC-2.31_buildpack", plugin: "GLIBC-2.34", reason: "the plugin requires GLIBC 2.34 or newer"},
+		"musl plugin":          {host: "GLIBC-2.31_buildpack", plugin: "MUSL", reason: "the plugin is linked against MUSL but the binary uses GLIBC"},
+		"glibc plugin":         {host: "MUSL-1.2.4_docker", plugin: "GLIBC-2.31", reason: "the plugin is linked against GLIBC but the binary uses MUSL"},
+		"musl without version": {host: "MUSL-1.2.4_docker", plugin: "MUSL"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			diffs := Descriptor{Libc: tc.host}.Compare(Descriptor{Libc: tc.plugin})
+			if tc.reason == "" {
+				require.Empty(t, diffs)
+				return
+			}
+			require.Equal(t, []Diff{{Name: "libc", Expected: tc.host, Have: tc.plugin, Kind: KindLibc, Reason: tc.reason}}, diffs)
+		})
+	}
+}
+
+func Test_parseBuildInfo_replace(t *testing.T) {
+	deps, settings := parseBuildInfo(&debug.BuildInfo{
+		Deps: []*debug.Module{
+			{Path: "golang.org/x/mod", Version: "v0.36.0", Replace: &debug.Module{Path: "golang.org/x/mod", Version: "v0.35.0"}},
+			{Path: "github.com/gin-gonic/gin", Version: "v1.9.1"},
+			{Path: "example.com/local", Version: "v1.0.0", Replace: &debug.Module{Path: "../local"}},
+		},
+		Settings: []debug.BuildSetting{{Key: "CGO_ENABLED", Value: "1"}},
+	})
+	require.Equal(t, map[string]string{
+		"golang.org/x/mod":         "v0.35.0",
+		"github.com/gin-gonic/gin": "v1.9.1",
+		"example.com/local":        "v1.0.0",
+	}, deps)
+	require.Equal(t, map[string]string{"CGO_ENABLED": "1"}, settings)
+}
@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"debug/elf"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Families of C libraries
+const (
+	LibcGlibc = "GLIBC"
+	LibcMusl  = "MUSL"
+)
+
+// Libc identifies the C library a binary was built against or requires
+type Libc struct {
+	Family  string
+	Version string
+}
+
+// String returns the libc with the format used by the KrakenD builds (GLIBC-2.31)
+func (l Libc) String() string {
+	if l.Version == "" {
+		return l.Family
+	}
+	return l.Family + "-" + l.Version
+}
+
+var libcPattern = regexp.MustCompile(`(?i)(glibc|musl)(?:[-_ ]?(\d+(?:\.\d+)*))?`)
+
+// ParseLibc extracts the libc family and version from strings like
+// GLIBC-2.31_buildpack, GLIBC_2.34 or MUSL-1.2.4_docker. It returns false
+// when the received value does not identify a C library (undefined, empty...)
+func ParseLibc(s string) (Libc, bool) {
+	m := libcPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Libc{}, false
+	}
+	return Libc{Family: strings.ToUpper(m[1]), Version: m[2]}, true
+}
+
+// RequiredLibc inspects the dynamic section of an ELF binary and returns the C
+// library it requires: the highest GLIBC_x.y symbol version referenced by the
+// binary or MUSL when it is linked against musl. It returns an empty string
+// when the binary does not depend on a known C library.
+func RequiredLibc(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("reading the ELF binary: %w", err)
+	}
+	defer f.Close()
+
+	libs, err := f.ImportedLibraries()
+	if err != nil {
+		return "", fmt.Errorf("reading the imported libraries: %w", err)
+	}
+	for _, lib := range libs {
+		if strings.Contains(lib, "musl") {
+			return LibcMusl, nil
+		}
+	}
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_INTERP {
+			continue
+		}
+		interp := make([]byte, p.Filesz)
+		if _, err := p.ReadAt(interp, 0); err == nil && strings.Contains(string(interp), "musl") {
+			return LibcMusl, nil
+		}
+	}
+
+	symbols, err := f.ImportedSymbols()
+	if err != nil {
+		return "", fmt.Errorf("reading the imported symbols: %w", err)
+	}
+	required := ""
+	for _, s := range symbols {
+		v, ok := strings.CutPrefix(s.Version, LibcGlibc+"_")
+		if !ok || !semver.IsValid("v"+v) {
+			continue
+		}
+		if required == "" || semver.Compare("v"+v, "v"+required) > 0 {
+			required = v
+		}
+	}
+	if required == "" {
+		return "", nil
+	}
+	return Libc{Family: LibcGlibc, Version: required}.String(), nil
+}
+
+// compareLibc checks if a plugin requiring the libc have can be loaded by a
+// binary built against the libc expected. Newer C libraries are backwards
+// compatible, so only plugins requiring a newer version or a different family
+// are incompatible. Unknown values are not reported.
+func compareLibc(expected, have string) (Diff, bool) {
+	host, ok := ParseLibc(expected)
+	if !ok {
+		return Diff{}, false
+	}
+	plugin, ok := ParseLibc(have)
+	if !ok {
+		return Diff{}, false
+	}
+
+	diff := Diff{Name: "libc", Expected: expected, Have: have, Kind: KindLibc}
+	if host.Family != plugin.Family {
+		diff.Reason = fmt.Sprintf("the plugin is linked against %s but the binary uses %s", plugin.Family, host.Family)
+		return diff, true
+	}
+	if host.Version == "" || plugin.Version == "" {
+		return Diff{}, false
+	}
+	if semver.Compare("v"+plugin.Version, "v"+host.Version) > 0 {
+		diff.Reason = fmt.Sprintf("the plugin requires %s %s or newer", plugin.Family, plugin.Version)
+		return diff, true
+	}
+	return Diff{}, false
+}
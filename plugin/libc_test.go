@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredLibc(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the test binary is not an ELF file")
+	}
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// the test binary is usually statically linked, and the system ones dynamically
+	for _, path := range []string{exe, "/bin/ls"} {
+		f, err := elf.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		require.NoError(t, err, path)
+		libs, err := f.ImportedLibraries()
+		f.Close()
+		require.NoError(t, err, path)
+
+		libc, err := RequiredLibc(path)
+		require.NoError(t, err, path)
+		switch {
+		case slices.ContainsFunc(libs, func(l string) bool { return strings.Contains(l, "musl") }):
+			require.Equal(t, LibcMusl, libc, path)
+		case slices.Contains(libs, "libc.so.6"):
+			// the binaries linked against glibc reference versioned symbols
+			l, ok := ParseLibc(libc)
+			require.True(t, ok, path)
+			require.Equal(t, LibcGlibc, l.Family, path)
+			require.NotEmpty(t, l.Version, path)
+		default:
+			require.Empty(t, libc, path)
+		}
+	}
+
+	notELF := filepath.Join(t.TempDir(), "plugin.so")
+	require.NoError(t, os.WriteFile(notELF, []byte("not a binary"), 0o600))
+	_, err = RequiredLibc(notELF)
+	require.ErrorContains(t, err, "reading the ELF binary")
+}
@@ -0,0 +1,408 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// Plugin types supported by the scaffolding
+const (
+	TypeHandler  = "handler"
+	TypeClient   = "client"
+	TypeModifier = "modifier"
+)
+
+// ScaffoldConfig defines the plugin module to generate
+type ScaffoldConfig struct {
+	// Type is the kind of plugin: handler, client or modifier
+	Type string
+	// Name is the name the plugin registers itself with
+	Name string
+	// Module is the module path declared in the go.mod
+	Module string
+}
+
+// Scaffold generates a plugin module in the dst folder with a go.mod pinned to the
+// Go version and the dependencies of the received descriptor, so the plugin is
+// compatible with the binary from its first build. The dependencies are pinned with
+// replace directives, as the generated code only imports the standard library and
+// go mod tidy would drop them as requirements. It returns the list of generated files.
+func Scaffold(dst string, cfg ScaffoldConfig, host Descriptor) ([]string, error) {
+	tmpls, ok := scaffoldTemplates[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown plugin type %q", cfg.Type)
+	}
+	if cfg.Name == "" {
+		return nil, errors.New("the plugin name is required")
+	}
+	if cfg.Module == "" {
+		// the plugins built locally do not need a dot in the first element
+		if err := module.CheckImportPath(cfg.Name); err != nil {
+			return nil, fmt.Errorf("the plugin name is not a valid module path, set the module: %w", err)
+		}
+		cfg.Module = cfg.Name
+	} else if err := module.CheckPath(cfg.Module); err != nil {
+		return nil, err
+	}
+
+	goMod, err := scaffoldGoMod(cfg.Module, host)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{"go.mod": goMod}
+	for name, content := range tmpls {
+		tmpl, err := template.New(name).Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		buf := new(bytes.Buffer)
+		if err := tmpl.Execute(buf, cfg); err != nil {
+			return nil, err
+		}
+		files[name] = buf.Bytes()
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, filepath.Join(dst, name))
+	}
+	sort.Strings(names)
+	for _, path := range names {
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("%s already exists", path)
+		}
+	}
+
+	if err := os.MkdirAll(dst, 0o755); err != nil { // skipcq: GSC-G301
+		return nil, err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dst, name), content, 0o644); err != nil { // skipcq: GSC-G306
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+func scaffoldGoMod(module string, host Descriptor) ([]byte, error) {
+	f := new(modfile.File)
+	if err := f.AddModuleStmt(module); err != nil {
+		return nil, err
+	}
+	if err := f.AddGoStmt(host.Go); err != nil {
+		return nil, fmt.Errorf("setting the go version: %w", err)
+	}
+	if err := f.AddToolchainStmt("go" + host.Go); err != nil {
+		return nil, fmt.Errorf("setting the toolchain: %w", err)
+	}
+
+	deps := make([]string, 0, len(host.Deps))
+	for dep := range host.Deps {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	for _, dep := range deps {
+		if err := f.AddReplace(dep, "", dep, host.Deps[dep]); err != nil {
+			return nil, fmt.Errorf("pinning %s: %w", dep, err)
+		}
+	}
+	f.Cleanup()
+	return f.Format()
+}
+
+var scaffoldTemplates = map[string]map[string]string{
+	TypeHandler: {
+		"main.go":      handlerMainTmpl,
+		"main_test.go": handlerTestTmpl,
+		"Makefile":     makefileTmpl,
+	},
+	TypeClient: {
+		"main.go":      clientMainTmpl,
+		"main_test.go": clientTestTmpl,
+		"Makefile":     makefileTmpl,
+	},
+	TypeModifier: {
+		"main.go":      modifierMainTmpl,
+		"main_test.go": modifierTestTmpl,
+		"Makefile":     makefileTmpl,
+	},
+}
+
+const makefileTmpl = `.PHONY: all deps test build check
+
+all: test build
+
+deps:
+	go mod tidy
+
+test: deps
+	go test ./...
+
+build: deps
+	go build -buildmode=plugin -o {{.Name}}.so .
+
+check: deps
+	krakend check-plugin -s ./go.sum
+`
+
+const loggerTmpl = `
+func (registerer) RegisterLogger(v interface{}) {
+	l, ok := v.(Logger)
+	if !ok {
+		return
+	}
+	logger = l
+	logger.Debug(fmt.Sprintf("[PLUGIN: %s] Logger loaded", pluginName))
+}
+
+// Logger is the interface of the logger injected by KrakenD
+type Logger interface {
+	Debug(v ...interface{})
+	Info(v ...interface{})
+	Warning(v ...interface{})
+	Error(v ...interface{})
+	Critical(v ...interface{})
+	Fatal(v ...interface{})
+}
+
+// noopLogger is used until KrakenD injects its own logger
+type noopLogger struct{}
+
+func (noopLogger) Debug(_ ...interface{})    {}
+func (noopLogger) Info(_ ...interface{})     {}
+func (noopLogger) Warning(_ ...interface{})  {}
+func (noopLogger) Error(_ ...interface{})    {}
+func (noopLogger) Critical(_ ...interface{}) {}
+func (noopLogger) Fatal(_ ...interface{})    {}
+
+func main() {}
+`
+
+const handlerMainTmpl = `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const pluginName = {{ printf "%q" .Name }}
+
+// HandlerRegisterer is the symbol KrakenD looks up to register the plugin
+var HandlerRegisterer = registerer(pluginName)
+
+var logger Logger = noopLogger{}
+
+type registerer string
+
+func (r registerer) RegisterHandlers(f func(
+	name string,
+	handler func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error),
+)) {
+	f(string(r), r.registerHandlers)
+}
+
+func (registerer) registerHandlers(_ context.Context, extra map[string]interface{}, h http.Handler) (http.Handler, error) {
+	cfg, ok := extra[pluginName].(map[string]interface{})
+	if !ok {
+		return h, errors.New("configuration not found")
+	}
+	logger.Debug(fmt.Sprintf("[PLUGIN: %s] Configuration loaded: %v", pluginName, cfg))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(w, req)
+	}), nil
+}
+` + loggerTmpl
+
+const handlerTestTmpl = `package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerRegisterer(t *testing.T) {
+	var registered string
+	var factory func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error)
+	HandlerRegisterer.RegisterHandlers(func(name string, f func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error)) {
+		registered = name
+		factory = f
+	})
+	if registered != pluginName {
+		t.Fatalf("unexpected name: %s", registered)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	h, err := factory(context.Background(), map[string]interface{}{pluginName: map[string]interface{}{}}, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("unexpected status code: %d", w.Code)
+	}
+}
+`
+
+const clientMainTmpl = `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const pluginName = {{ printf "%q" .Name }}
+
+// ClientRegisterer is the symbol KrakenD looks up to register the plugin
+var ClientRegisterer = registerer(pluginName)
+
+var logger Logger = noopLogger{}
+
+type registerer string
+
+func (r registerer) RegisterClients(f func(
+	name string,
+	handler func(context.Context, map[string]interface{}) (http.Handler, error),
+)) {
+	f(string(r), r.registerClients)
+}
+
+func (registerer) registerClients(_ context.Context, extra map[string]interface{}) (http.Handler, error) {
+	cfg, ok := extra[pluginName].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("configuration not found")
+	}
+	logger.Debug(fmt.Sprintf("[PLUGIN: %s] Configuration loaded: %v", pluginName, cfg))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer resp.Body.Close()
+
+		for k, hs := range resp.Header {
+			for _, h := range hs {
+				w.Header().Add(k, h)
+			}
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}), nil
+}
+` + loggerTmpl
+
+const clientTestTmpl = `package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClientRegisterer(t *testing.T) {
+	var registered string
+	var factory func(context.Context, map[string]interface{}) (http.Handler, error)
+	ClientRegisterer.RegisterClients(func(name string, f func(context.Context, map[string]interface{}) (http.Handler, error)) {
+		registered = name
+		factory = f
+	})
+	if registered != pluginName {
+		t.Fatalf("unexpected name: %s", registered)
+	}
+
+	if _, err := factory(context.Background(), map[string]interface{}{}); err == nil {
+		t.Error("expecting an error without configuration")
+	}
+	if _, err := factory(context.Background(), map[string]interface{}{pluginName: map[string]interface{}{}}); err != nil {
+		t.Error(err)
+	}
+}
+`
+
+const modifierMainTmpl = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+const pluginName = {{ printf "%q" .Name }}
+
+// ModifierRegisterer is the symbol KrakenD looks up to register the plugin
+var ModifierRegisterer = registerer(pluginName)
+
+var logger Logger = noopLogger{}
+
+type registerer string
+
+func (r registerer) RegisterModifiers(f func(
+	name string,
+	factoryFunc func(map[string]interface{}) func(interface{}) (interface{}, error),
+	appliesToRequest bool,
+	appliesToResponse bool,
+)) {
+	f(string(r), r.modifierFactory, true, false)
+}
+
+func (registerer) modifierFactory(extra map[string]interface{}) func(interface{}) (interface{}, error) {
+	cfg, ok := extra[pluginName].(map[string]interface{})
+	if !ok {
+		return func(interface{}) (interface{}, error) {
+			return nil, errors.New("configuration not found")
+		}
+	}
+	logger.Debug(fmt.Sprintf("[PLUGIN: %s] Configuration loaded: %v", pluginName, cfg))
+
+	return func(input interface{}) (interface{}, error) {
+		return input, nil
+	}
+}
+` + loggerTmpl
+
+const modifierTestTmpl = `package main
+
+import (
+	"testing"
+)
+
+func TestModifierRegisterer(t *testing.T) {
+	var registered string
+	var factory func(map[string]interface{}) func(interface{}) (interface{}, error)
+	ModifierRegisterer.RegisterModifiers(func(name string, f func(map[string]interface{}) func(interface{}) (interface{}, error), _, _ bool) {
+		registered = name
+		factory = f
+	})
+	if registered != pluginName {
+		t.Fatalf("unexpected name: %s", registered)
+	}
+
+	modifier := factory(map[string]interface{}{pluginName: map[string]interface{}{}})
+	out, err := modifier("input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "input" {
+		t.Errorf("unexpected output: %v", out)
+	}
+}
+`
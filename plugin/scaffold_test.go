@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+func TestScaffold(t *testing.T) {
+	host := Descriptor{
+		Go: "1.25.3",
+		Deps: map[string]string{
+			"github.com/gin-gonic/gin": "v1.9.1",
+			"golang.org/x/mod":         "v0.35.0",
+		},
+	}
+
+	for _, pluginType := range []string{TypeHandler, TypeClient, TypeModifier} {
+		t.Run(pluginType, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "my-plugin")
+
+			files, err := Scaffold(dst, ScaffoldConfig{Type: pluginType, Name: "my-plugin", Module: "example.com/my-plugin"}, host)
+			require.NoError(t, err)
+			require.Equal(t, []string{
+				filepath.Join(dst, "Makefile"),
+				filepath.Join(dst, "go.mod"),
+				filepath.Join(dst, "main.go"),
+				filepath.Join(dst, "main_test.go"),
+			}, files)
+
+			data, err := os.ReadFile(filepath.Join(dst, "go.mod"))
+			require.NoError(t, err)
+			f, err := modfile.Parse("go.mod", data, nil)
+			require.NoError(t, err)
+			require.Equal(t, "example.com/my-plugin", f.Module.Mod.Path)
+			require.Equal(t, "1.25.3", f.Go.Version)
+			require.Equal(t, "go1.25.3", f.Toolchain.Name)
+			// the generated code only imports the standard library, so the host
+			// dependencies are pinned with replace directives
+			require.Empty(t, f.Require)
+			require.Len(t, f.Replace, 2)
+			for _, r := range f.Replace {
+				require.Equal(t, r.Old.Path, r.New.Path)
+				require.Empty(t, r.Old.Version)
+				require.Equal(t, host.Deps[r.Old.Path], r.New.Version)
+			}
+
+			_, err = Scaffold(dst, ScaffoldConfig{Type: pluginType, Name: "my-plugin"}, host)
+			require.EqualError(t, err, filepath.Join(dst, "Makefile")+" already exists")
+		})
+	}
+
+	_, err := Scaffold(t.TempDir(), ScaffoldConfig{Type: "unknown", Name: "my-plugin"}, host)
+	require.EqualError(t, err, `unknown plugin type "unknown"`)
+
+	_, err = Scaffold(t.TempDir(), ScaffoldConfig{Type: TypeHandler, Name: "my plugin"}, host)
+	require.ErrorContains(t, err, "the plugin name is not a valid module path, set the module")
+	_, err = Scaffold(t.TempDir(), ScaffoldConfig{Type: TypeHandler, Name: "my-plugin", Module: "my-plugin"}, host)
+	require.ErrorContains(t, err, "missing dot in first path element")
+}
+
+func TestScaffold_quotedName(t *testing.T) {
+	dst := t.TempDir()
+	name := `my "quoted" \plugin`
+	_, err := Scaffold(dst, ScaffoldConfig{Type: TypeModifier, Name: name, Module: "example.com/my-plugin"}, Descriptor{Go: "1.25.3"})
+	require.NoError(t, err)
+
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dst, "main.go"), nil, 0)
+	require.NoError(t, err)
+	for _, d := range f.Decls {
+		g, ok := d.(*ast.GenDecl)
+		if !ok || g.Tok != token.CONST {
+			continue
+		}
+		lit := g.Specs[0].(*ast.ValueSpec).Values[0].(*ast.BasicLit)
+		v, err := strconv.Unquote(lit.Value)
+		require.NoError(t, err)
+		require.Equal(t, name, v)
+		return
+	}
+	t.Error("pluginName not found")
+}
@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/krakend/krakend-cobra/v2/plugin"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
 )
 
 func Test_pluginFuncErr(t *testing.T) {
@@ -32,6 +35,10 @@ func Test_pluginFuncErr(t *testing.T) {
 		goSum    string
 		expected string
 		fix      bool
+		fixMod   bool
+		dryRun   bool
+		host     string
+		flags    map[string]string
 		err      string
 	}{
 		"missing": {
@@ -65,6 +72,56 @@ go get golang.org/x/mod@v0.6.0-dev.0.20220419223038-86c51ed26bb4
 `,
 			err: "3 incompatibilities found",
 		},
+		"fix go.mod dry-run": {
+			goSum:  "./testdata/changes-go.sum",
+			fixMod: true,
+			dryRun: true,
+			expected: `--- testdata/go.mod
++++ testdata/go.mod
+@@ -11,3 +11,9 @@
+ 	cloud.google.com/go v0.100.2 // indirect
+ 	github.com/Azure/azure-sdk-for-go v59.3.0+incompatible // indirect
+ )
++
++replace cloud.google.com/go => cloud.google.com/go v0.100.2
++
++replace github.com/Azure/azure-sdk-for-go => github.com/Azure/azure-sdk-for-go v59.3.0+incompatible
++
++replace golang.org/x/mod => golang.org/x/mod v0.6.0-dev.0.20220419223038-86c51ed26bb4
+`,
+			err: "3 incompatibilities found",
+		},
+		"host descriptor": {
+			goSum: "./testdata/changes-go.sum",
+			host:  "./testdata/host-descriptor.json",
+			expected: `github.com/Azure/azure-sdk-for-go
+	have: v59.3.1+incompatible
+	want: v59.3.0+incompatible
+`,
+			err: "1 incompatibilities found",
+		},
+		"host descriptor with explicit versions": {
+			goSum: "./testdata/changes-go.sum",
+			host:  "./testdata/host-descriptor.json",
+			flags: map[string]string{"go": "1.24.0", "libc": "GLIBC-2.38"},
+			expected: `libc
+	have: GLIBC-2.38
+	want: GLIBC-2.36
+	why: the plugin requires GLIBC 2.38 or newer
+go
+	have: 1.24.0
+	want: 1.25.3
+github.com/Azure/azure-sdk-for-go
+	have: v59.3.1+incompatible
+	want: v59.3.0+incompatible
+`,
+			err: "3 incompatibilities found",
+		},
+		"missing host descriptor": {
+			goSum: "./testdata/changes-go.sum",
+			host:  "./testdata/missing-descriptor.json",
+			err:   "open ./testdata/missing-descriptor.json: no such file or directory",
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -78,7 +135,27 @@ go get golang.org/x/mod@v0.6.0-dev.0.20220419223038-86c51ed26bb4
 			gogetEnabled = tc.fix
 			defer func() { gogetEnabled = fix }()
 
-			err := pluginFuncErr(cmd, nil)
+			fixMod, dryRun := fixEnabled, fixDryRun
+			fixEnabled, fixDryRun = tc.fixMod, tc.dryRun
+			defer func() { fixEnabled, fixDryRun = fixMod, dryRun }()
+
+			// the --go and --libc defaults are left alone, so the ones of the host apply
+			origHost, origGo, origLibc := hostDescriptor, goVersion, libcVersion
+			hostDescriptor = tc.host
+			defer func() { hostDescriptor, goVersion, libcVersion = origHost, origGo, origLibc }()
+
+			c := cmd
+			if len(tc.flags) > 0 {
+				c = &cobra.Command{}
+				c.SetOutput(&buf)
+				c.Flags().StringVar(&goVersion, "go", goVersion, "")
+				c.Flags().StringVar(&libcVersion, "libc", libcVersion, "")
+				for k, v := range tc.flags {
+					require.NoError(t, c.Flags().Set(k, v))
+				}
+			}
+
+			err := pluginFuncErr(c, nil)
 			if tc.err != "" {
 				require.EqualError(t, err, tc.err)
 			} else {
@@ -88,3 +165,92 @@ go get golang.org/x/mod@v0.6.0-dev.0.20220419223038-86c51ed26bb4
 		})
 	}
 }
+
+func Test_fixGoMod(t *testing.T) {
+	original, err := os.ReadFile("./testdata/go.mod")
+	require.NoError(t, err)
+
+	defer func(s string, dryRun bool) { goSum, fixDryRun = s, dryRun }(goSum, fixDryRun)
+	fixDryRun = false
+
+	for name, tc := range map[string]struct {
+		diffs    []plugin.Diff
+		require  map[string]string
+		replace  map[string]string
+		goV      string
+		err      string
+		modified bool
+	}{
+		"go and dependencies": {
+			diffs: []plugin.Diff{
+				{Name: "go", Expected: "1.25.3", Have: "1.17", Kind: plugin.KindGo},
+				{Name: "github.com/gin-gonic/gin", Expected: "v1.9.1", Have: "v1.8.2", Kind: plugin.KindDependency},
+				{Name: "cloud.google.com/go", Expected: "v0.100.1", Have: "v0.100.2", Kind: plugin.KindDependency},
+				// already required at the host version, but a higher one is selected
+				{Name: "golang.org/x/mod", Expected: "v0.6.0-dev.0.20220419223038-86c51ed26bb4", Have: "v0.7.0", Kind: plugin.KindDependency},
+			},
+			goV: "1.25.3",
+			require: map[string]string{
+				"github.com/gin-gonic/gin":          "v1.9.1",
+				"golang.org/x/mod":                  "v0.6.0-dev.0.20220419223038-86c51ed26bb4",
+				"cloud.google.com/go":               "v0.100.2",
+				"github.com/Azure/azure-sdk-for-go": "v59.3.0+incompatible",
+			},
+			replace: map[string]string{
+				"cloud.google.com/go": "v0.100.1",
+				"golang.org/x/mod":    "v0.6.0-dev.0.20220419223038-86c51ed26bb4",
+			},
+			modified: true,
+		},
+		"only libc and build": {
+			diffs: []plugin.Diff{
+				{Name: "libc", Expected: "GLIBC-2.36", Have: "GLIBC-2.38", Kind: plugin.KindLibc},
+				{Name: "CGO_ENABLED", Expected: "1", Have: "0", Kind: plugin.KindBuild},
+			},
+			err: "2 incompatibilities can not be fixed automatically",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), original, 0o600))
+			goSum = filepath.Join(dir, "go.sum")
+
+			var stdout, stderr bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+
+			err := fixGoMod(cmd, tc.diffs)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			require.NoError(t, err)
+			if !tc.modified {
+				require.Equal(t, string(original), string(data))
+				require.NotContains(t, stdout.String(), "go.mod updated")
+				return
+			}
+			require.Contains(t, stdout.String(), "+++ "+filepath.Join(dir, "go.mod"))
+			require.Contains(t, stdout.String(), "go.mod updated")
+
+			f, err := modfile.Parse("go.mod", data, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.goV, f.Go.Version)
+			require.Equal(t, "go"+tc.goV, f.Toolchain.Name)
+			requires := map[string]string{}
+			for _, r := range f.Require {
+				requires[r.Mod.Path] = r.Mod.Version
+			}
+			require.Equal(t, tc.require, requires)
+			replaces := map[string]string{}
+			for _, r := range f.Replace {
+				replaces[r.Old.Path] = r.New.Version
+			}
+			require.Equal(t, tc.replace, replaces)
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+)
+
+// Finding is an element of the configuration violating a rule
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Location identifies the endpoint, backend or agent violating the rule. It is
+	// empty for the service scope.
+	Location string `json:"location,omitempty"`
+	// Candidates are the elements the finding probably refers to, when the rule does
+	// not report them, like the built-in ones. They are a guess, so the finding is
+	// still reported once and only suppressed when all of them are.
+	Candidates []string `json:"candidates,omitempty"`
+	// Position is where the element is declared in the source of the configuration,
+	// when known by the caller
+	Position *source.Position `json:"position,omitempty"`
+}
+
+// Evaluate returns the findings of the rules on the configuration, in the order of
+// the rules and the elements
+func Evaluate(cfg config.ServiceConfig, rules []Rule) []Finding {
+	var findings []Finding
+	for _, r := range rules {
+		for _, e := range elements(cfg, r.Scope) {
+			if !all(r.When, e.value) || all(r.Assert, e.value) {
+				continue
+			}
+			msg := r.Message
+			if e.location != "" {
+				msg = e.location + ": " + msg
+			}
+			findings = append(findings, Finding{
+				Rule:     r.ID,
+				Severity: r.Severity,
+				Message:  msg,
+				Location: e.location,
+			})
+		}
+	}
+	return findings
+}
+
+type element struct {
+	location string
+	value    interface{}
+}
+
+func elements(cfg config.ServiceConfig, scope string) []element {
+	var res []element
+	switch scope {
+	case ScopeService:
+		res = append(res, element{value: toGeneric(reflect.ValueOf(cfg))})
+	case ScopeEndpoint:
+		for _, e := range cfg.Endpoints {
+			res = append(res, element{location: EndpointLocation(e), value: toGeneric(reflect.ValueOf(e))})
+		}
+	case ScopeAgent:
+		for _, a := range cfg.AsyncAgents {
+			res = append(res, element{location: AgentLocation(a), value: toGeneric(reflect.ValueOf(a))})
+		}
+	case ScopeBackend:
+		for _, e := range cfg.Endpoints {
+			for _, b := range e.Backend {
+				res = append(res, element{location: BackendLocation(EndpointLocation(e), b), value: toGeneric(reflect.ValueOf(b))})
+			}
+		}
+		for _, a := range cfg.AsyncAgents {
+			for _, b := range a.Backend {
+				res = append(res, element{location: BackendLocation(AgentLocation(a), b), value: toGeneric(reflect.ValueOf(b))})
+			}
+		}
+	}
+	return res
+}
+
+// EndpointLocation identifies the endpoint in the findings
+func EndpointLocation(e *config.EndpointConfig) string {
+	return e.Method + " " + e.Endpoint
+}
+
+// AgentLocation identifies the async agent in the findings
+func AgentLocation(a *config.AsyncAgent) string {
+	return "agent " + a.Name
+}
+
+// BackendLocation identifies the backend of the endpoint or agent at the parent
+// location in the findings
+func BackendLocation(parent string, b *config.Backend) string {
+	return parent + " -> " + b.URLPattern
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// toGeneric converts the config structs into maps keyed as in the configuration files
+func toGeneric(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toGeneric(v.Elem())
+	case reflect.Struct:
+		res := map[string]interface{}{}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			key := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+			if key == "" || key == "-" || !f.IsExported() {
+				continue
+			}
+			res[key] = toGeneric(v.Field(i))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		res := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res[fmt.Sprintf("%v", iter.Key().Interface())] = toGeneric(iter.Value())
+		}
+		return res
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		res := make([]interface{}, v.Len())
+		for i := range res {
+			res[i] = toGeneric(v.Index(i))
+		}
+		return res
+	case reflect.Func, reflect.Chan:
+		return nil
+	default:
+		return v.Interface()
+	}
+}
+
+func all(conditions []Condition, v interface{}) bool {
+	for _, c := range conditions {
+		if !c.holds(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Condition) compile() error {
+	if c.Op == "" {
+		c.Op = OpExists
+	}
+	segments, err := parsePath(c.Path)
+	if err != nil {
+		return err
+	}
+	c.segments = segments
+
+	c.values = nil
+	if list, ok := c.Value.([]interface{}); ok {
+		c.values = list
+	} else if c.Value != nil {
+		c.values = []interface{}{c.Value}
+	}
+
+	switch c.Op {
+	case OpExists, OpAbsent:
+		return nil
+	case OpEquals:
+	case OpMatches, OpGlob:
+		c.patterns = make([]*regexp.Regexp, len(c.values))
+		for i, v := range c.values {
+			s := fmt.Sprintf("%v", v)
+			if c.Op == OpGlob {
+				s = globToRegexp(s)
+			}
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return fmt.Errorf("path %s: %w", c.Path, err)
+			}
+			c.patterns[i] = re
+		}
+	case OpLt, OpGt:
+		if len(c.values) != 1 {
+			return fmt.Errorf("path %s: the %s operator requires a single value", c.Path, c.Op)
+		}
+		if _, ok := toFloat(c.values[0]); !ok {
+			return fmt.Errorf("path %s: the %s operator requires a number", c.Path, c.Op)
+		}
+	default:
+		return fmt.Errorf("path %s: unknown operator %s", c.Path, c.Op)
+	}
+	if len(c.values) == 0 {
+		return fmt.Errorf("path %s: the %s operator requires a value", c.Path, c.Op)
+	}
+	return nil
+}
+
+func (c Condition) holds(v interface{}) bool {
+	values := resolve(v, c.segments)
+
+	var res bool
+	switch c.Op {
+	case OpExists:
+		res = len(values) > 0
+	case OpAbsent:
+		res = len(values) == 0
+	default:
+		res = c.All
+		for _, val := range values {
+			if c.match(val) != c.All {
+				res = !c.All
+				break
+			}
+		}
+	}
+	return res != c.Not
+}
+
+func (c Condition) match(v interface{}) bool {
+	switch c.Op {
+	case OpEquals:
+		for _, want := range c.values {
+			if equal(v, want) {
+				return true
+			}
+		}
+	case OpMatches, OpGlob:
+		s, ok := scalar(v)
+		if !ok {
+			return false
+		}
+		for _, re := range c.patterns {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+	case OpLt, OpGt:
+		have, ok := toFloat(v)
+		if !ok {
+			return false
+		}
+		want, _ := toFloat(c.values[0])
+		if c.Op == OpLt {
+			return have < want
+		}
+		return have > want
+	}
+	return false
+}
+
+// resolve returns the non-null values found at the path
+func resolve(v interface{}, segments []string) []interface{} {
+	if len(segments) == 0 {
+		if v == nil {
+			return nil
+		}
+		return []interface{}{v}
+	}
+
+	var children []interface{}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if segments[0] == "*" {
+			for _, c := range t {
+				children = append(children, c)
+			}
+		} else if c, ok := t[segments[0]]; ok {
+			children = append(children, c)
+		}
+	case []interface{}:
+		if segments[0] == "*" {
+			children = t
+		} else if i, err := strconv.Atoi(segments[0]); err == nil && i >= 0 && i < len(t) {
+			children = append(children, t[i])
+		}
+	}
+
+	var res []interface{}
+	for _, c := range children {
+		res = append(res, resolve(c, segments[1:])...)
+	}
+	return res
+}
+
+// parsePath splits the path into its keys. The keys with dots are quoted with
+// brackets: a.b["c.d"].e
+func parsePath(path string) ([]string, error) {
+	var segments []string
+	rest := path
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("path %s: unclosed bracket", path)
+			}
+			key, err := strconv.Unquote(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("path %s: invalid quoted key %s", path, rest[1:end])
+			}
+			segments = append(segments, key)
+			rest = strings.TrimPrefix(rest[end+1:], ".")
+			continue
+		}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			segments = append(segments, rest)
+			break
+		}
+		if end > 0 {
+			segments = append(segments, rest[:end])
+		}
+		if rest[end] == '.' {
+			end++
+		}
+		rest = rest[end:]
+	}
+	if len(segments) == 0 {
+		return nil, errors.New("empty path")
+	}
+	return segments, nil
+}
+
+func globToRegexp(pattern string) string {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return "^" + strings.Join(parts, ".*") + "$"
+}
+
+func scalar(v interface{}) (string, bool) {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return "", false
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
+
+func equal(have, want interface{}) bool {
+	if a, ok := toFloat(have); ok {
+		b, ok := toFloat(want)
+		return ok && a == b
+	}
+	a, ok := scalar(have)
+	if !ok {
+		return false
+	}
+	b, ok := scalar(want)
+	return ok && a == b
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case uint16:
+		return float64(t), true
+	case uint64:
+		return float64(t), true
+	case float64:
+		return t, true
+	case float32:
+		return float64(t), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,177 @@
+// Package policy evaluates organization-specific audit rules declared in JSON or YAML
+// files against a KrakenD configuration
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Scopes of the rules
+const (
+	ScopeService  = "service"
+	ScopeEndpoint = "endpoint"
+	ScopeBackend  = "backend"
+	ScopeAgent    = "agent"
+)
+
+// Operators of the conditions
+const (
+	OpExists  = "exists"
+	OpAbsent  = "absent"
+	OpEquals  = "equals"
+	OpMatches = "matches"
+	OpGlob    = "glob"
+	OpLt      = "lt"
+	OpGt      = "gt"
+)
+
+// Severities are the accepted severities of the rules
+var Severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+
+// Rule is a custom audit rule. It is evaluated on every element of its scope selected
+// by the When conditions, and reports a finding for the elements not satisfying all
+// the Assert conditions.
+type Rule struct {
+	ID       string      `yaml:"id"`
+	Severity string      `yaml:"severity"`
+	Message  string      `yaml:"message"`
+	Scope    string      `yaml:"scope"`
+	When     []Condition `yaml:"when"`
+	Assert   []Condition `yaml:"assert"`
+	// URL points to the documentation of the rule
+	URL string `yaml:"url"`
+	// Rationale explains why the rule matters, and Remediation is the extra_config
+	// to add to the elements violating it. The strings of the remediation can use the
+	// {{.Endpoint}}, {{.Method}} and {{.URLPattern}} placeholders.
+	Rationale   string                 `yaml:"rationale"`
+	Remediation map[string]interface{} `yaml:"remediation"`
+}
+
+// Condition checks the values found at the path of an element.
+//
+// The path is a dot-separated list of keys as written in the configuration file. Keys
+// containing dots can be quoted with brackets (extra_config["github.com/devopsfaith/krakend-ratelimit/juju/router"])
+// and the * segment selects all the items of an array or an object.
+//
+// The condition holds when any of the values satisfies the operator, or all of them
+// when All is set. The Value of equals, matches and glob can be a list of
+// alternatives. Not negates the result.
+type Condition struct {
+	Path  string      `yaml:"path"`
+	Op    string      `yaml:"op"`
+	Value interface{} `yaml:"value"`
+	All   bool        `yaml:"all"`
+	Not   bool        `yaml:"not"`
+
+	segments []string
+	values   []interface{}
+	patterns []*regexp.Regexp
+}
+
+// Namespace returns the extra_config namespace checked by the assertions of the rule,
+// if any
+func (r Rule) Namespace() string {
+	for _, c := range r.Assert {
+		if len(c.segments) > 1 && c.segments[0] == "extra_config" && c.segments[1] != "*" {
+			return c.segments[1]
+		}
+	}
+	return ""
+}
+
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Load reads the rules from the file or from all the .json, .yml and .yaml files in
+// the directory, sorted by name
+func Load(path string) ([]Rule, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = files[:0]
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".json", ".yml", ".yaml":
+				if !e.IsDir() {
+					files = append(files, filepath.Join(path, e.Name()))
+				}
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var rules []Rule
+	ids := map[string]string{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		// YAML is a superset of JSON, so the same decoder reads both formats
+		var rf ruleFile
+		if err := yaml.Unmarshal(b, &rf); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		for i, r := range rf.Rules {
+			if err := r.validate(); err != nil {
+				return nil, fmt.Errorf("%s: rule #%d: %w", f, i, err)
+			}
+			if other, ok := ids[r.ID]; ok {
+				return nil, fmt.Errorf("%s: rule %s already defined in %s", f, r.ID, other)
+			}
+			ids[r.ID] = f
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rule) validate() error {
+	if r.ID == "" {
+		return errors.New("missing id")
+	}
+	if r.Message == "" {
+		return fmt.Errorf("%s: missing message", r.ID)
+	}
+	r.Severity = strings.ToUpper(r.Severity)
+	if !slices.Contains(Severities, r.Severity) {
+		return fmt.Errorf("%s: unknown severity %q", r.ID, r.Severity)
+	}
+	if r.Scope == "" {
+		r.Scope = ScopeEndpoint
+	}
+	switch r.Scope {
+	case ScopeService, ScopeEndpoint, ScopeBackend, ScopeAgent:
+	default:
+		return fmt.Errorf("%s: unknown scope %s", r.ID, r.Scope)
+	}
+	if len(r.Assert) == 0 {
+		return fmt.Errorf("%s: no assertions", r.ID)
+	}
+	for _, cs := range [][]Condition{r.When, r.Assert} {
+		for i := range cs {
+			if err := cs[i].compile(); err != nil {
+				return fmt.Errorf("%s: %w", r.ID, err)
+			}
+		}
+	}
+	return nil
+}
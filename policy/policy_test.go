@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	rules, err := Load("testdata")
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+
+	cfg := config.ServiceConfig{
+		Endpoints: []*config.EndpointConfig{
+			{
+				Endpoint:    "/users",
+				Method:      "POST",
+				ExtraConfig: config.ExtraConfig{"auth/validator": map[string]interface{}{"alg": "RS256"}},
+				Backend: []*config.Backend{
+					{URLPattern: "/users", Host: []string{"http://users.internal:8080"}},
+				},
+			},
+			{
+				Endpoint: "/orders",
+				Method:   "POST",
+				Backend: []*config.Backend{
+					{URLPattern: "/orders", Host: []string{"https://orders.example.com", "http://orders.example.com"}},
+				},
+			},
+			{
+				Endpoint:    "/public",
+				Method:      "GET",
+				ExtraConfig: config.ExtraConfig{"qos/ratelimit/router": map[string]interface{}{"max_rate": 10.0}},
+				Backend: []*config.Backend{
+					{URLPattern: "/public", Host: []string{"https://public.example.com"}},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, []Finding{
+		{Rule: "ORG-001", Severity: "HIGH", Message: "POST /orders: POST endpoints must be protected with auth/validator", Location: "POST /orders"},
+		{Rule: "ORG-002", Severity: "CRITICAL", Message: "POST /orders -> /orders: backends must use https outside the internal network", Location: "POST /orders -> /orders"},
+		{Rule: "ORG-003", Severity: "MEDIUM", Message: "POST /orders: public endpoints must be rate limited", Location: "POST /orders"},
+		{Rule: "ORG-004", Severity: "LOW", Message: "the global timeout must be set"},
+	}, Evaluate(cfg, rules))
+}
+
+func TestLoad_errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content string
+		err     string
+	}{
+		"missing id":       {content: `rules: [{message: m, severity: LOW, assert: [{path: a}]}]`, err: "rule #0: missing id"},
+		"unknown severity": {content: `rules: [{id: R, message: m, severity: HUGE, assert: [{path: a}]}]`, err: `rule #0: R: unknown severity "HUGE"`},
+		"unknown scope":    {content: `rules: [{id: R, message: m, severity: LOW, scope: x, assert: [{path: a}]}]`, err: "rule #0: R: unknown scope x"},
+		"no assertions":    {content: `rules: [{id: R, message: m, severity: LOW}]`, err: "rule #0: R: no assertions"},
+		"unknown operator": {content: `rules: [{id: R, message: m, severity: LOW, assert: [{path: a, op: like}]}]`, err: "rule #0: R: path a: unknown operator like"},
+		"missing value":    {content: `rules: [{id: R, message: m, severity: LOW, assert: [{path: a, op: equals}]}]`, err: "rule #0: R: path a: the equals operator requires a value"},
+		"invalid regexp":   {content: `rules: [{id: R, message: m, severity: LOW, assert: [{path: a, op: matches, value: "("}]}]`, err: "rule #0: R: path a: error parsing regexp"},
+		"bad path":         {content: `rules: [{id: R, message: m, severity: LOW, assert: [{path: 'a["b'}]}]`, err: "rule #0: R: path a[\"b: unclosed bracket"},
+		"duplicated id":    {content: `rules: [{id: R, message: m, severity: LOW, assert: [{path: a}]}, {id: R, message: m, severity: LOW, assert: [{path: a}]}]`, err: "rule R already defined"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+			_, err := Load(path)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	for path, expected := range map[string][]string{
+		"method":                           {"method"},
+		"extra_config.auth/validator.alg":  {"extra_config", "auth/validator", "alg"},
+		`extra_config["github.com/a.b"].x`: {"extra_config", "github.com/a.b", "x"},
+		"backend.*.host.0":                 {"backend", "*", "host", "0"},
+	} {
+		segments, err := parsePath(path)
+		require.NoError(t, err, path)
+		require.Equal(t, expected, segments, path)
+	}
+}
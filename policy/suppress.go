@@ -0,0 +1,279 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// InlineSuppressionKey is the extra_config key of the endpoints and backends listing
+// the suppressions of the findings on them. As the rest of keys starting with @, it is
+// ignored by the gateway.
+const InlineSuppressionKey = "@audit_ignore"
+
+// dateLayout is the format of the expiration dates
+const dateLayout = "2006-01-02"
+
+// Suppression is a documented exception to a rule
+type Suppression struct {
+	Rule string `yaml:"rule"`
+	// Selector is a glob matched against the location of the findings, like
+	// "POST /users" or "GET /users/* -> *". An empty selector matches all the
+	// findings of the rule, including the ones without location. The built-in rules
+	// about the service have no location, so they only accept an empty selector.
+	Selector string `yaml:"selector"`
+	Reason   string `yaml:"reason"`
+	Owner    string `yaml:"owner"`
+	// Expires is the last day the suppression applies (YYYY-MM-DD). Empty for the
+	// permanent ones.
+	Expires string `yaml:"expires"`
+	// Source is the file or the element of the config declaring the suppression
+	Source string `yaml:"-"`
+
+	selector *regexp.Regexp
+	expires  time.Time
+	// location is the element declaring an inline suppression. Its findings are
+	// matched by the exact location, as the paths can contain {} and *.
+	location string
+	// nested extends the inline suppression to the backends of the element
+	nested bool
+}
+
+// Suppressed is a finding hidden by a suppression
+type Suppressed struct {
+	Finding
+	Reason  string `json:"reason"`
+	Owner   string `json:"owner,omitempty"`
+	Expires string `json:"expires,omitempty"`
+	Source  string `json:"source"`
+}
+
+type suppressionFile struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// LoadSuppressions reads the suppressions of a JSON or YAML file
+func LoadSuppressions(path string) ([]Suppression, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sf suppressionFile
+	if err := yaml.Unmarshal(b, &sf); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range sf.Suppressions {
+		sf.Suppressions[i].Source = path
+		if err := sf.Suppressions[i].compile(); err != nil {
+			return nil, fmt.Errorf("%s: suppression #%d: %w", path, i, err)
+		}
+	}
+	return sf.Suppressions, nil
+}
+
+// InlineSuppressions returns the suppressions declared in the extra_config of the
+// endpoints and backends. They apply to the findings of the element declaring them,
+// and the suppressions of an endpoint also apply to its backends.
+func InlineSuppressions(cfg config.ServiceConfig) ([]Suppression, error) {
+	var res []Suppression
+	add := func(extra config.ExtraConfig, location string, nested bool) error {
+		v, ok := extra[InlineSuppressionKey]
+		if !ok {
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var ss []Suppression
+		if err := yaml.Unmarshal(b, &ss); err != nil {
+			return fmt.Errorf("%s: %s: %w", location, InlineSuppressionKey, err)
+		}
+		for i := range ss {
+			ss[i].Selector = ""
+			ss[i].Source = location
+			ss[i].location = location
+			ss[i].nested = nested
+			if err := ss[i].compile(); err != nil {
+				return fmt.Errorf("%s: %s #%d: %w", location, InlineSuppressionKey, i, err)
+			}
+		}
+		res = append(res, ss...)
+		return nil
+	}
+
+	for _, e := range cfg.Endpoints {
+		location := EndpointLocation(e)
+		if err := add(e.ExtraConfig, location, true); err != nil {
+			return nil, err
+		}
+		for _, b := range e.Backend {
+			if err := add(b.ExtraConfig, BackendLocation(location, b), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, a := range cfg.AsyncAgents {
+		location := AgentLocation(a)
+		if err := add(a.ExtraConfig, location, true); err != nil {
+			return nil, err
+		}
+		for _, b := range a.Backend {
+			if err := add(b.ExtraConfig, BackendLocation(location, b), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+func (s *Suppression) compile() error {
+	if s.Rule == "" {
+		return errors.New("missing rule")
+	}
+	if s.Reason == "" {
+		return fmt.Errorf("%s: missing reason", s.Rule)
+	}
+	if s.Expires != "" {
+		t, err := time.Parse(dateLayout, s.Expires)
+		if err != nil {
+			return fmt.Errorf("%s: invalid expiration date %q, use the YYYY-MM-DD format", s.Rule, s.Expires)
+		}
+		s.expires = t
+	}
+	if s.Selector != "" {
+		re, err := regexp.Compile(selectorToRegexp(s.Selector))
+		if err != nil {
+			return fmt.Errorf("%s: invalid selector: %w", s.Rule, err)
+		}
+		s.selector = re
+	}
+	return nil
+}
+
+// selectorToRegexp converts the glob into a regexp, where * matches any sequence of
+// characters and {a,b} any of the alternatives
+func selectorToRegexp(glob string) string {
+	res := "^"
+	inGroup := false
+	for _, r := range glob {
+		switch {
+		case r == '*':
+			res += ".*"
+		case r == '{' && !inGroup:
+			inGroup = true
+			res += "(?:"
+		case r == '}' && inGroup:
+			inGroup = false
+			res += ")"
+		case r == ',' && inGroup:
+			res += "|"
+		default:
+			res += regexp.QuoteMeta(string(r))
+		}
+	}
+	return res + "$"
+}
+
+// Matches reports if the suppression targets the finding
+func (s Suppression) Matches(f Finding) bool {
+	if s.Rule != f.Rule {
+		return false
+	}
+	if s.location != "" {
+		return f.Location == s.location || (s.nested && strings.HasPrefix(f.Location, s.location+" -> "))
+	}
+	return s.selector == nil || s.selector.MatchString(f.Location)
+}
+
+// Scoped reports if the suppression only applies to the findings of some elements:
+// the ones with a selector and the inline ones
+func (s Suppression) Scoped() bool {
+	return s.Selector != "" || s.location != ""
+}
+
+// Expired reports if the suppression no longer applies at the given time
+func (s Suppression) Expired(now time.Time) bool {
+	return !s.expires.IsZero() && now.After(s.expires.AddDate(0, 0, 1))
+}
+
+// Suppress splits the findings into the ones to report and the suppressed ones. The
+// findings matching only expired suppressions are reported with a note about the
+// expiration. The findings without location but with Candidates are suppressed when
+// every candidate is.
+func Suppress(findings []Finding, suppressions []Suppression, now time.Time) ([]Finding, []Suppressed) {
+	var kept []Finding
+	var suppressed []Suppressed
+
+	for _, f := range findings {
+		active, expired := matchSuppression(f, suppressions, now)
+		if active == nil && f.Location == "" && len(f.Candidates) > 0 {
+			active, expired = matchCandidates(f, suppressions, now)
+		}
+
+		switch {
+		case active != nil:
+			suppressed = append(suppressed, Suppressed{
+				Finding: f,
+				Reason:  active.Reason,
+				Owner:   active.Owner,
+				Expires: active.Expires,
+				Source:  active.Source,
+			})
+		case expired != nil:
+			f.Message = fmt.Sprintf("%s (suppression by %s expired on %s: %s)", f.Message, ownerOrSource(*expired), expired.Expires, expired.Reason)
+			kept = append(kept, f)
+		default:
+			kept = append(kept, f)
+		}
+	}
+	return kept, suppressed
+}
+
+// matchSuppression returns the first active suppression of the finding, or the last
+// expired one when none is active
+func matchSuppression(f Finding, suppressions []Suppression, now time.Time) (*Suppression, *Suppression) {
+	var expired *Suppression
+	for i := range suppressions {
+		s := &suppressions[i]
+		if !s.Matches(f) {
+			continue
+		}
+		if s.Expired(now) {
+			expired = s
+			continue
+		}
+		return s, nil
+	}
+	return nil, expired
+}
+
+// matchCandidates returns the suppression of the first candidate of the finding when
+// all of them are suppressed
+func matchCandidates(f Finding, suppressions []Suppression, now time.Time) (*Suppression, *Suppression) {
+	var first *Suppression
+	for _, c := range f.Candidates {
+		active, expired := matchSuppression(Finding{Rule: f.Rule, Location: c}, suppressions, now)
+		if active == nil {
+			return nil, expired
+		}
+		if first == nil {
+			first = active
+		}
+	}
+	return first, nil
+}
+
+func ownerOrSource(s Suppression) string {
+	if s.Owner != "" {
+		return s.Owner
+	}
+	return s.Source
+}
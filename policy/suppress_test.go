@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuppress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`suppressions:
+  - rule: ORG-002
+    selector: "* /orders -> *"
+    reason: the orders service has no TLS yet
+    owner: team-orders
+    expires: 2026-03-31
+  - rule: ORG-004
+    reason: the timeout is set by the load balancer
+    owner: platform
+`), 0o600))
+
+	fromFile, err := LoadSuppressions(path)
+	require.NoError(t, err)
+
+	inline, err := InlineSuppressions(config.ServiceConfig{
+		Endpoints: []*config.EndpointConfig{
+			{
+				Endpoint: "/orders",
+				Method:   "POST",
+				ExtraConfig: config.ExtraConfig{
+					InlineSuppressionKey: []interface{}{
+						map[string]interface{}{"rule": "ORG-001", "reason": "authenticated by the mesh", "expires": "2026-12-31"},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, inline, 1)
+	require.Equal(t, "POST /orders", inline[0].Source)
+
+	findings := []Finding{
+		{Rule: "ORG-001", Severity: "HIGH", Message: "POST /orders: m1", Location: "POST /orders"},
+		{Rule: "ORG-001", Severity: "HIGH", Message: "POST /users: m1", Location: "POST /users"},
+		{Rule: "ORG-002", Severity: "CRITICAL", Message: "POST /orders -> /orders: m2", Location: "POST /orders -> /orders"},
+		{Rule: "ORG-004", Severity: "LOW", Message: "m4"},
+	}
+
+	kept, suppressed := Suppress(findings, append(fromFile, inline...), time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC))
+	require.Equal(t, []Finding{findings[1]}, kept)
+	require.Equal(t, []Suppressed{
+		{Finding: findings[0], Reason: "authenticated by the mesh", Expires: "2026-12-31", Source: "POST /orders"},
+		{Finding: findings[2], Reason: "the orders service has no TLS yet", Owner: "team-orders", Expires: "2026-03-31", Source: path},
+		{Finding: findings[3], Reason: "the timeout is set by the load balancer", Owner: "platform", Source: path},
+	}, suppressed)
+
+	kept, suppressed = Suppress(findings, append(fromFile, inline...), time.Date(2026, 4, 1, 1, 0, 0, 0, time.UTC))
+	require.Len(t, suppressed, 2)
+	require.Equal(t, []Finding{
+		findings[1],
+		{
+			Rule:     "ORG-002",
+			Severity: "CRITICAL",
+			Message:  "POST /orders -> /orders: m2 (suppression by team-orders expired on 2026-03-31: the orders service has no TLS yet)",
+			Location: "POST /orders -> /orders",
+		},
+	}, kept)
+}
+
+func TestInlineSuppressions_pathParams(t *testing.T) {
+	ignore := func(rule string) config.ExtraConfig {
+		return config.ExtraConfig{InlineSuppressionKey: []interface{}{map[string]interface{}{"rule": rule, "reason": "r"}}}
+	}
+	inline, err := InlineSuppressions(config.ServiceConfig{
+		Endpoints: []*config.EndpointConfig{
+			{
+				Endpoint:    "/users/{id}",
+				Method:      "GET",
+				ExtraConfig: ignore("ORG-001"),
+				Backend: []*config.Backend{
+					{URLPattern: "/users/{id}/*", ExtraConfig: ignore("ORG-002")},
+				},
+			},
+			{Endpoint: "/users/{id}/orders", Method: "GET"},
+		},
+	})
+	require.NoError(t, err)
+
+	findings := []Finding{
+		{Rule: "ORG-001", Location: "GET /users/{id}"},
+		{Rule: "ORG-001", Location: "GET /users/{id} -> /users/{id}/*"},
+		{Rule: "ORG-002", Location: "GET /users/{id} -> /users/{id}/*"},
+		{Rule: "ORG-001", Location: "GET /users/{id}/orders"},
+		{Rule: "ORG-002", Location: "GET /users/{id} -> /users/{id}/a"},
+	}
+	kept, suppressed := Suppress(findings, inline, time.Now())
+	require.Equal(t, findings[3:], kept)
+	require.Len(t, suppressed, 3)
+}
+
+func TestSuppress_candidates(t *testing.T) {
+	ignore := func(rule, expires string) config.ExtraConfig {
+		return config.ExtraConfig{InlineSuppressionKey: []interface{}{map[string]interface{}{"rule": rule, "reason": "r", "expires": expires}}}
+	}
+	inline, err := InlineSuppressions(config.ServiceConfig{
+		Endpoints: []*config.EndpointConfig{
+			{Endpoint: "/a", Method: "GET", ExtraConfig: ignore("3.1.2", "")},
+			{Endpoint: "/b", Method: "GET", ExtraConfig: ignore("3.1.2", "")},
+			{Endpoint: "/c", Method: "GET", ExtraConfig: ignore("1.1.1", "2020-01-01")},
+		},
+	})
+	require.NoError(t, err)
+
+	findings := []Finding{
+		{Rule: "3.1.2", Message: "all", Candidates: []string{"GET /a", "GET /b"}},
+		{Rule: "3.1.2", Message: "some", Candidates: []string{"GET /a", "GET /d"}},
+		{Rule: "1.1.1", Message: "expired", Candidates: []string{"GET /c"}},
+	}
+	kept, suppressed := Suppress(findings, inline, time.Now())
+	require.Equal(t, []Suppressed{{Finding: findings[0], Reason: "r", Source: "GET /a"}}, suppressed)
+	require.Equal(t, []Finding{
+		findings[1],
+		{Rule: "1.1.1", Message: "expired (suppression by GET /c expired on 2020-01-01: r)", Candidates: []string{"GET /c"}},
+	}, kept)
+}
+
+func TestLoadSuppressions_errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content string
+		err     string
+	}{
+		"missing rule":   {content: `suppressions: [{reason: r}]`, err: "suppression #0: missing rule"},
+		"missing reason": {content: `suppressions: [{rule: R}]`, err: "suppression #0: R: missing reason"},
+		"bad date":       {content: `suppressions: [{rule: R, reason: r, expires: 31/12/2026}]`, err: `suppression #0: R: invalid expiration date "31/12/2026"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "suppressions.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+			_, err := LoadSuppressions(path)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
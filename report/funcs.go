@@ -0,0 +1,359 @@
+// Package report renders the results of the audit with go templates, providing a
+// library of helper functions and a set of built-in templates
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	audit "github.com/krakend/krakend-audit"
+	"github.com/krakend/krakend-cobra/v2/source"
+)
+
+// DocsURL is the documentation page of the built-in audit rules
+var DocsURL = "https://www.krakend.io/docs/configuration/audit/"
+
+// RuleURLs maps the IDs of the rules to their documentation. The built-in rules not
+// listed here point to the section of DocsURL named after them.
+var RuleURLs = map[string]string{}
+
+// Severities sorts the severities from the most to the least severe
+var Severities = []string{audit.SeverityCritical, audit.SeverityHigh, audit.SeverityMedium, audit.SeverityLow}
+
+// FuncMap returns the functions available to the templates
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"marshal": func(v interface{}) string {
+			a, _ := json.Marshal(v)
+			return string(a)
+		},
+		"colored": colored,
+
+		// strings
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(n int, s string) string { return strings.Repeat(s, n) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"default":    defaultValue,
+		"indent":     indent,
+		"nindent":    func(n int, s string) string { return "\n" + indent(n, s) },
+
+		// lists
+		"list":    func(v ...interface{}) []interface{} { return v },
+		"first":   first,
+		"last":    last,
+		"uniq":    uniq,
+		"has":     has,
+		"count":   count,
+		"sortBy":  sortBy,
+		"groupBy": groupBy,
+
+		// dicts
+		"dict":   dict,
+		"get":    func(d map[string]interface{}, k string) interface{} { return d[k] },
+		"set":    func(d map[string]interface{}, k string, v interface{}) map[string]interface{} { d[k] = v; return d },
+		"hasKey": func(d map[string]interface{}, k string) bool { _, ok := d[k]; return ok },
+		"keys":   keys,
+
+		// encoding
+		"toYaml":         toYaml,
+		"markdownEscape": markdownEscape,
+		"htmlEscape":     html.EscapeString,
+
+		"ruleURL": RuleURL,
+	}
+}
+
+// RuleURL returns the documentation of the rule, or an empty string when it is unknown
+func RuleURL(rule string) string {
+	if u, ok := RuleURLs[rule]; ok {
+		return u
+	}
+	parts := strings.Split(rule, ".")
+	for _, p := range parts {
+		if p == "" || strings.Trim(p, "0123456789") != "" {
+			return ""
+		}
+	}
+	return DocsURL + "#" + strings.Join(parts, "")
+}
+
+func colored(s string) string {
+	switch s {
+	case audit.SeverityLow:
+		return fmt.Sprintf("\033[32;1m%s\033[0m", s)
+	case audit.SeverityMedium:
+		return fmt.Sprintf("\033[33;1m%s\033[0m", s)
+	case audit.SeverityHigh:
+		return fmt.Sprintf("\033[31;1m%s\033[0m", s)
+	case audit.SeverityCritical:
+		return fmt.Sprintf("\033[41;1m%s\033[0m", s)
+	default:
+		return s
+	}
+}
+
+func title(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+	}
+	return strings.Join(words, " ")
+}
+
+func join(sep string, v interface{}) (string, error) {
+	items, err := toList(v)
+	if err != nil {
+		return "", err
+	}
+	res := make([]string, len(items))
+	for i, item := range items {
+		res[i] = fmt.Sprintf("%v", item)
+	}
+	return strings.Join(res, sep), nil
+}
+
+func defaultValue(def, v interface{}) interface{} {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	if rv.IsZero() || (isList(rv) || rv.Kind() == reflect.Map) && rv.Len() == 0 {
+		return def
+	}
+	return v
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func first(v interface{}) (interface{}, error) {
+	items, err := toList(v)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func last(v interface{}) (interface{}, error) {
+	items, err := toList(v)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[len(items)-1], nil
+}
+
+func uniq(v interface{}) ([]interface{}, error) {
+	items, err := toList(v)
+	if err != nil {
+		return nil, err
+	}
+	var res []interface{}
+	for _, item := range items {
+		if !slices.ContainsFunc(res, func(e interface{}) bool { return reflect.DeepEqual(e, item) }) {
+			res = append(res, item)
+		}
+	}
+	return res, nil
+}
+
+func has(needle, v interface{}) (bool, error) {
+	items, err := toList(v)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(items, func(e interface{}) bool { return reflect.DeepEqual(e, needle) }), nil
+}
+
+// count returns the number of items of the list, or the number of items with the
+// field set to the value when called as: count "Severity" "HIGH" .Recommendations
+func count(args ...interface{}) (int, error) {
+	switch len(args) {
+	case 1:
+		items, err := toList(args[0])
+		return len(items), err
+	case 3:
+		name, ok := args[0].(string)
+		if !ok {
+			return 0, errors.New("count: the field name must be a string")
+		}
+		items, err := toList(args[2])
+		if err != nil {
+			return 0, err
+		}
+		n := 0
+		for _, item := range items {
+			if fmt.Sprintf("%v", field(item, name)) == fmt.Sprintf("%v", args[1]) {
+				n++
+			}
+		}
+		return n, nil
+	default:
+		return 0, errors.New("count: use count LIST or count FIELD VALUE LIST")
+	}
+}
+
+// sortBy sorts the items by the field. The severities are sorted from the most to the
+// least severe, and the rest of values alphabetically.
+func sortBy(name string, v interface{}) ([]interface{}, error) {
+	items, err := toList(v)
+	if err != nil {
+		return nil, err
+	}
+	res := slices.Clone(items)
+	sort.SliceStable(res, func(i, j int) bool {
+		return less(field(res[i], name), field(res[j], name))
+	})
+	return res, nil
+}
+
+// Group is a set of items sharing the value of a field
+type Group struct {
+	Key   string
+	Items []interface{}
+}
+
+// groupBy groups the items by the field, in the order used by sortBy
+func groupBy(name string, v interface{}) ([]Group, error) {
+	items, err := sortBy(name, v)
+	if err != nil {
+		return nil, err
+	}
+	var res []Group
+	for _, item := range items {
+		key := fmt.Sprintf("%v", field(item, name))
+		if len(res) == 0 || res[len(res)-1].Key != key {
+			res = append(res, Group{Key: key})
+		}
+		res[len(res)-1].Items = append(res[len(res)-1].Items, item)
+	}
+	return res, nil
+}
+
+func dict(kv ...interface{}) (map[string]interface{}, error) {
+	if len(kv)%2 != 0 {
+		return nil, errors.New("dict: expected an even number of arguments")
+	}
+	res := make(map[string]interface{}, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		k, ok := kv[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: the key %v is not a string", kv[i])
+		}
+		res[k] = kv[i+1]
+	}
+	return res, nil
+}
+
+func keys(d map[string]interface{}) []string {
+	res := make([]string, 0, len(d))
+	for k := range d {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// toYaml renders the value as YAML, keeping the names and order of its JSON
+// representation
+func toYaml(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	doc, err := source.Decode(b, source.JSON)
+	if err != nil {
+		return "", err
+	}
+	out, err := source.Encode(doc, source.YAML, "  ")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "{", `\{`, "}", `\}`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`, "\n", " ",
+)
+
+func markdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+func toList(v interface{}) ([]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v)
+	if !isList(rv) {
+		return nil, fmt.Errorf("expected a list, got %T", v)
+	}
+	res := make([]interface{}, rv.Len())
+	for i := range res {
+		res[i] = rv.Index(i).Interface()
+	}
+	return res, nil
+}
+
+func isList(rv reflect.Value) bool {
+	return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+}
+
+// field returns the value of the field (case-insensitive) of a struct, or of the key
+// of a map
+func field(v interface{}, name string) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		f := rv.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+		if f.IsValid() {
+			return f.Interface()
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			if val := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())); val.IsValid() {
+				return val.Interface()
+			}
+		}
+	}
+	return nil
+}
+
+func less(a, b interface{}) bool {
+	sa, sb := fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)
+	ra, rb := slices.Index(Severities, sa), slices.Index(Severities, sb)
+	if ra >= 0 && rb >= 0 {
+		return ra < rb
+	}
+	if fa, ok := a.(int); ok {
+		if fb, ok := b.(int); ok {
+			return fa < fb
+		}
+	}
+	return sa < sb
+}
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	audit "github.com/krakend/krakend-audit"
+	"github.com/stretchr/testify/require"
+)
+
+type testData struct {
+	Recommendations []audit.Recommendation
+	Suppressed      []audit.Recommendation
+	Summary         map[string]int
+	Files           []interface{}
+	Explanations    []testExplanation
+}
+
+type testExplanation struct {
+	Rule, Title, Rationale string
+	Links                  []string
+	Remediations           []struct{ Location, Snippet string }
+}
+
+var data = testData{
+	Recommendations: []audit.Recommendation{
+		{Rule: "2.1.3", Severity: audit.SeverityLow, Message: "Use *the* [debug] endpoint | carefully"},
+		{Rule: "1.1.1", Severity: audit.SeverityCritical, Message: "Implement more secure <alternatives>"},
+		{Rule: "ORG-001", Severity: audit.SeverityLow, Message: "custom"},
+	},
+	Summary: map[string]int{"Total": 3, "Critical": 1, "High": 0, "Medium": 0, "Low": 2, "Suppressed": 0, "Score": 74},
+	Explanations: []testExplanation{{
+		Rule:         "1.1.1",
+		Title:        "Implement more secure alternatives than Basic Auth",
+		Links:        []string{DocsURL + "#111"},
+		Remediations: []struct{ Location, Snippet string }{{Location: "GET /users", Snippet: `{"endpoint": "/users"}`}},
+	}},
+}
+
+func TestNew_funcs(t *testing.T) {
+	RuleURLs["ORG-001"] = "https://wiki.example.com/org-001"
+	defer delete(RuleURLs, "ORG-001")
+
+	for tmpl, expected := range map[string]string{
+		`{{ range groupBy "Severity" .Recommendations }}{{.Key}}={{ len .Items }} {{ end }}`:        "CRITICAL=1 LOW=2 ",
+		`{{ range sortBy "Rule" .Recommendations }}{{.Rule}} {{ end }}`:                             "1.1.1 2.1.3 ORG-001 ",
+		`{{ count .Recommendations }}/{{ count "Severity" "LOW" .Recommendations }}`:                "3/2",
+		`{{ ruleURL "1.1.1" }} {{ ruleURL "ORG-001" }} [{{ ruleURL "X" }}]`:                         DocsURL + "#111 https://wiki.example.com/org-001 []",
+		`{{ markdownEscape (index .Recommendations 0).Message }}`:                                   `Use \*the\* \[debug\] endpoint \| carefully`,
+		`{{ htmlEscape (index .Recommendations 1).Message }}`:                                       "Implement more secure &lt;alternatives&gt;",
+		`{{ (first .Recommendations).Rule | lower }} {{ (last .Recommendations).Rule | title }}`:    "2.1.3 Org-001",
+		`{{ $d := dict "a" 1 "b" 2 }}{{ keys $d | join "," }} {{ get $d "b" }} {{ hasKey $d "c" }}`: "a,b 2 false",
+		`{{ list "a" "b" "a" | uniq | join "-" }} {{ has "b" (list "a" "b") }}`:                     "a-b true",
+		`{{ default "none" "" }} {{ "a.b" | replace "." "/" | upper }} {{ trimPrefix "x" "xy" }}`:   "none A/B y",
+		`{{ index .Recommendations 1 | toYaml | indent 2 }}`:                                        "  rule: 1.1.1\n  severity: CRITICAL\n  message: Implement more secure <alternatives>",
+	} {
+		tpl, err := New(tmpl)
+		require.NoError(t, err, tmpl)
+		var buf bytes.Buffer
+		require.NoError(t, tpl.Execute(&buf, data), tmpl)
+		require.Equal(t, expected, buf.String(), tmpl)
+	}
+}
+
+func TestNew_sources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`{{ .Summary.Total }} results`), 0o600))
+
+	tpl, err := New("@" + path)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, tpl.Execute(&buf, data))
+	require.Equal(t, "3 results", buf.String())
+
+	_, err = New("@" + filepath.Join(t.TempDir(), "unknown.tmpl"))
+	require.Error(t, err)
+
+	_, err = New("{{ unknownFunc }}")
+	require.ErrorContains(t, err, `function "unknownFunc" not defined`)
+
+	for _, name := range Names() {
+		tpl, err := New(name)
+		require.NoError(t, err, name)
+		buf.Reset()
+		require.NoError(t, tpl.Execute(&buf, data), name)
+		require.Contains(t, buf.String(), "1.1.1", name)
+	}
+
+	tpl, err = New("markdown")
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, tpl.Execute(&buf, data))
+	require.Contains(t, buf.String(), "## Critical\n\n| Rule | Message |\n| --- | --- |\n| [1.1.1]("+DocsURL+"#111) | Implement more secure \\<alternatives\\> |\n")
+	require.Contains(t, buf.String(), "### 1.1.1: Implement more secure alternatives than Basic Auth\n\n- <"+DocsURL+"#111>\n\n`GET /users`:\n\n```json\n{\"endpoint\": \"/users\"}\n```\n")
+}
+
+func TestScore(t *testing.T) {
+	require.Equal(t, MaxScore, Score(nil, 0))
+	// a penalty of 20*2+4+1 with an allowance of 20*3
+	require.Equal(t, 57, Score([]audit.Recommendation{
+		{Rule: "1.1.1", Severity: audit.SeverityCritical},
+		{Rule: "3.1.2", Severity: audit.SeverityMedium},
+		{Rule: "ORG-001", Severity: audit.SeverityLow},
+	}, 3))
+
+	// the score keeps improving when fixing the recommendations of a bad configuration
+	var recs []audit.Recommendation
+	prev := MaxScore
+	for i := 0; i < 10; i++ {
+		recs = append(recs, audit.Recommendation{Rule: "1.1.1", Severity: audit.SeverityCritical})
+		score := Score(recs, 5)
+		require.Less(t, score, prev)
+		require.Positive(t, score)
+		prev = score
+	}
+	require.Equal(t, "custom", Category("ORG-001"))
+	require.Equal(t, "2", Category("2.1.1"))
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"math"
+	"strings"
+
+	audit "github.com/krakend/krakend-audit"
+)
+
+// MaxScore is the score of a configuration without recommendations
+const MaxScore = 100
+
+// SeverityWeights are the penalty of every recommendation, by severity
+var SeverityWeights = map[string]float64{
+	audit.SeverityCritical: 20,
+	audit.SeverityHigh:     10,
+	audit.SeverityMedium:   4,
+	audit.SeverityLow:      1,
+}
+
+// CategoryWeights multiply the weight of the recommendations of a category. The
+// categories not listed weigh 1.
+var CategoryWeights = map[string]float64{
+	// security
+	"1": 2,
+}
+
+// Category returns the category of the rule: the first number of the ID for the
+// built-in rules, and "custom" for the rest of them
+func Category(rule string) string {
+	first, _, _ := strings.Cut(rule, ".")
+	if first == "" || strings.Trim(first, "0123456789") != "" {
+		return "custom"
+	}
+	return first
+}
+
+// ElementAllowance is the penalty halving the score of a configuration with a single
+// element. Every endpoint, backend and async agent adds its allowance, so the score
+// measures the recommendations per element.
+const ElementAllowance = 20
+
+// Score rates the recommendations from 0 to MaxScore for a configuration with the
+// number of elements. The score decreases with the weight of every recommendation
+// without reaching 0, so fixing any of them always raises it.
+func Score(recommendations []audit.Recommendation, elements int) int {
+	penalty := 0.0
+	for _, r := range recommendations {
+		w := 1.0
+		if cw, ok := CategoryWeights[Category(r.Rule)]; ok {
+			w = cw
+		}
+		penalty += SeverityWeights[r.Severity] * w
+	}
+	allowance := ElementAllowance * float64(max(elements, 1))
+	return int(math.Round(MaxScore * allowance / (allowance + penalty)))
+}
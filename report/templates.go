@@ -0,0 +1,132 @@
+package report
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Templates are the built-in templates, selected by name
+var Templates = map[string]string{
+	"table":    tableTmpl,
+	"markdown": markdownTmpl,
+	"html":     htmlTmpl,
+}
+
+// Names returns the names of the built-in templates
+func Names() []string {
+	res := make([]string, 0, len(Templates))
+	for name := range Templates {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// New parses the template defined by the spec: the name of a built-in template, the
+// path to a template file prefixed with @, or the template itself
+func New(spec string) (*template.Template, error) {
+	text := spec
+	if t, ok := Templates[spec]; ok {
+		text = t
+	} else if path, ok := strings.CutPrefix(spec, "@"); ok {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	}
+	return template.New("audit").Funcs(FuncMap()).Parse(text)
+}
+
+const tableTmpl = `{{ printf "%-10s %-10s %s" "RULE" "SEVERITY" "MESSAGE" }}
+{{ range sortBy "Severity" .Recommendations }}{{ printf "%-10s %-10s %s" .Rule .Severity .Message }}
+{{ end }}{{ if .Suppressed }}
+{{ printf "%-10s %-10s %-30s %s" "SUPPRESSED" "SEVERITY" "OWNER" "REASON" }}
+{{ range sortBy "Severity" .Suppressed }}{{ printf "%-10s %-10s %-30s %s" .Rule .Severity (default "-" .Owner) .Reason }}
+{{ end }}{{ end }}{{ with .Summary }}
+{{.Total}} recommendation(s): {{.Critical}} CRITICAL, {{.High}} HIGH, {{.Medium}} MEDIUM, {{.Low}} LOW{{ if .Suppressed }}, {{.Suppressed}} suppressed{{ end }}{{ if .Hidden }}, {{.Hidden}} hidden by severity{{ end }}. Score: {{.Score}}/100
+{{ end }}`
+
+const markdownTmpl = `# KrakenD audit report
+{{ with .Summary }}
+**{{.Total}}** recommendation(s): {{.Critical}} critical, {{.High}} high, {{.Medium}} medium and {{.Low}} low{{ if .Suppressed }}. {{.Suppressed}} suppressed{{ end }}{{ if .Hidden }}. {{.Hidden}} hidden by severity{{ end }}. Score: **{{.Score}}**/100.
+{{ end }}{{ if .Files }}
+## Files
+
+| File | Score | Total | Critical | High | Medium | Low | Suppressed | Error |
+| --- | --- | --- | --- | --- | --- | --- | --- | --- |
+{{ range .Files }}| {{ markdownEscape .File }} | {{ with .Summary }}{{.Score}} | {{.Total}} | {{.Critical}} | {{.High}} | {{.Medium}} | {{.Low}} | {{.Suppressed}}{{ end }} | {{ markdownEscape .Error }} |
+{{ end }}{{ end }}{{ range groupBy "Severity" .Recommendations }}
+## {{ title .Key }}
+
+| Rule | Message |
+| --- | --- |
+{{ range .Items }}{{ $url := ruleURL .Rule }}| {{ if $url }}[{{ markdownEscape .Rule }}]({{ $url }}){{ else }}{{ markdownEscape .Rule }}{{ end }} | {{ markdownEscape .Message }} |
+{{ end }}{{ end }}{{ if .Suppressed }}
+## Suppressed
+
+| Rule | Severity | Message | Reason | Owner | Expires |
+| --- | --- | --- | --- | --- | --- |
+{{ range .Suppressed }}| {{ markdownEscape .Rule }} | {{ .Severity }} | {{ markdownEscape .Message }} | {{ markdownEscape .Reason }} | {{ markdownEscape .Owner }} | {{ .Expires }} |
+{{ end }}{{ end }}{{ if .Explanations }}
+## Explanations
+{{ range .Explanations }}
+### {{ markdownEscape .Rule }}{{ with .Title }}: {{ markdownEscape . }}{{ end }}
+{{ with .Rationale }}
+{{ markdownEscape . }}
+{{ end }}{{ range .Links }}
+- <{{ . }}>{{ end }}
+{{ range .Remediations }}
+{{ with .Location }}` + "`{{ . }}`" + `{{ else }}Service{{ end }}:
+
+` + "```json" + `
+{{ .Snippet }}
+` + "```" + `
+{{ end }}{{ end }}{{ end }}`
+
+const htmlTmpl = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>KrakenD audit report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: .4em .8em; text-align: left; }
+.CRITICAL { background: #b71c1c; color: #fff; }
+.HIGH { background: #e53935; color: #fff; }
+.MEDIUM { background: #fb8c00; }
+.LOW { background: #43a047; color: #fff; }
+</style>
+</head>
+<body>
+<h1>KrakenD audit report</h1>
+{{ with .Summary }}<p><strong>{{.Total}}</strong> recommendation(s): {{.Critical}} critical, {{.High}} high, {{.Medium}} medium and {{.Low}} low{{ if .Suppressed }}. {{.Suppressed}} suppressed{{ end }}{{ if .Hidden }}. {{.Hidden}} hidden by severity{{ end }}. Score: <strong>{{.Score}}</strong>/100.</p>
+{{ end }}{{ if .Files }}<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Score</th><th>Total</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Suppressed</th><th>Error</th></tr>
+{{ range .Files }}<tr><td>{{ htmlEscape .File }}</td>{{ with .Summary }}<td>{{.Score}}</td><td>{{.Total}}</td><td>{{.Critical}}</td><td>{{.High}}</td><td>{{.Medium}}</td><td>{{.Low}}</td><td>{{.Suppressed}}</td>{{ end }}<td>{{ htmlEscape .Error }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ range groupBy "Severity" .Recommendations }}<h2>{{ title .Key }}</h2>
+<table>
+<tr><th>Rule</th><th>Severity</th><th>Message</th></tr>
+{{ range .Items }}{{ $url := ruleURL .Rule }}<tr><td>{{ if $url }}<a href="{{ htmlEscape $url }}">{{ htmlEscape .Rule }}</a>{{ else }}{{ htmlEscape .Rule }}{{ end }}</td><td class="{{ .Severity }}">{{ .Severity }}</td><td>{{ htmlEscape .Message }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ if .Suppressed }}<h2>Suppressed</h2>
+<table>
+<tr><th>Rule</th><th>Severity</th><th>Message</th><th>Reason</th><th>Owner</th><th>Expires</th></tr>
+{{ range .Suppressed }}<tr><td>{{ htmlEscape .Rule }}</td><td class="{{ .Severity }}">{{ .Severity }}</td><td>{{ htmlEscape .Message }}</td><td>{{ htmlEscape .Reason }}</td><td>{{ htmlEscape .Owner }}</td><td>{{ .Expires }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ if .Explanations }}<h2>Explanations</h2>
+{{ range .Explanations }}<h3>{{ htmlEscape .Rule }}{{ with .Title }}: {{ htmlEscape . }}{{ end }}</h3>
+{{ with .Rationale }}<p>{{ htmlEscape . }}</p>
+{{ end }}{{ if .Links }}<ul>
+{{ range .Links }}<li><a href="{{ htmlEscape . }}">{{ htmlEscape . }}</a></li>
+{{ end }}</ul>
+{{ end }}{{ range .Remediations }}<p>{{ with .Location }}<code>{{ htmlEscape . }}</code>{{ else }}Service{{ end }}:</p>
+<pre>{{ htmlEscape .Snippet }}</pre>
+{{ end }}{{ end }}{{ end }}</body>
+</html>
+`
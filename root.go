@@ -4,7 +4,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/krakend/krakend-cobra/v2/plugin"
 	"github.com/luraproject/lura/v2/config"
 	"github.com/luraproject/lura/v2/core"
 	"github.com/mattn/go-isatty"
@@ -22,10 +24,41 @@ var (
 	lintCurrentSchema    bool
 	lintCustomSchemaPath string
 	lintNoNetwork        bool
+	checkRedactKeys      string
+	checkNoRedact        bool
+	checkEffective       bool
+	checkFilterPath      string
+	checkFilterMethod    string
+	checkFilterHost      string
+	checkFilterNamespace string
+	checkInspectCerts    bool
+	graphFormat          = "dot"
+	graphGroupByHost     bool
+	graphCollapse        bool
+	graphMarks           string
+	statsOutput          = "text"
+	statsMaxEndpoints    int
+	statsMaxBackends     int
+	statsAmplification   int
+	statsMaxTimeout      time.Duration
+	statsRequireAuth     bool
+	fmtIndent            = "  "
+	fmtCheck             bool
+	fmtDiff              bool
+	fmtSortEndpoints     bool
+	convertTo            string
+	convertOutput        string
+	convertIndent        = "  "
+	convertNoVerify      bool
 	rawEmbedSchema       string
 	rulesToExclude       string
 	rulesToExcludePath   string
 	severitiesToInclude  = "CRITICAL,HIGH,MEDIUM,LOW"
+	auditRulesPath       string
+	auditFailOn          string
+	auditOutputFiles     []string
+	auditHistoryDir      string
+	auditExplain         bool
 	formatTmpl           string
 	parser               config.Parser
 	run                  func(config.ServiceConfig)
@@ -35,14 +68,28 @@ var (
 	libcVersion     = core.GlibcVersion
 	checkDumpPrefix = "\t"
 	gogetEnabled    = false
+	fixEnabled      = false
+	fixDryRun       = false
+	hostDescriptor  string
+	pluginBinary    string
+	versionOutput   = "text"
+	scaffoldType    = plugin.TypeHandler
+	scaffoldName    string
+	scaffoldModule  string
 
-	DefaultRoot    Root
-	RootCommand    Command
-	RunCommand     Command
-	CheckCommand   Command
-	PluginCommand  Command
-	VersionCommand Command
-	AuditCommand   Command
+	DefaultRoot     Root
+	RootCommand     Command
+	RunCommand      Command
+	CheckCommand    Command
+	PluginCommand   Command
+	ScaffoldCommand Command
+	VersionCommand  Command
+	AuditCommand    Command
+	ExportCommand   Command
+	StatsCommand    Command
+	FmtCommand      Command
+	ConvertCommand  Command
+	LspCommand      Command
 
 	rootCmd = &cobra.Command{
 		Use:   "krakend",
@@ -50,9 +97,9 @@ var (
 	}
 
 	checkCmd = &cobra.Command{
-		Use:     "check",
+		Use:     "check [config files or globs...]",
 		Short:   "Validates that the configuration file is valid.",
-		Long:    "Validates that the active configuration file has a valid syntax to run the service.\nChange the configuration file by using the --config flag",
+		Long:    "Validates that the active configuration file has a valid syntax to run the service.\nChange the configuration file by using the --config flag, or pass several files and globs as arguments to check all of them",
 		Run:     checkFunc,
 		Aliases: []string{"validate"},
 		Example: "krakend check -d -l -c config.json",
@@ -71,7 +118,68 @@ var (
 		Short:   "Checks your plugin dependencies are compatible.",
 		Long:    "Checks your plugin dependencies are compatible and proposes commands to update your dependencies.",
 		Run:     pluginFunc,
-		Example: "krakend check-plugin -g 1.19.0 -s ./go.sum -f",
+		Example: "krakend check-plugin -g 1.19.0 -s ./go.sum -f\n  krakend check-plugin -s ./go.sum --fix --dry-run",
+	}
+
+	scaffoldCmd = &cobra.Command{
+		Use:   "plugin",
+		Short: "Helpers for developing KrakenD plugins.",
+		Long:  "Helpers for developing KrakenD plugins.",
+	}
+
+	scaffoldInitCmd = &cobra.Command{
+		Use:     "init [folder]",
+		Short:   "Generates a plugin module compatible with this binary.",
+		Long:    "Generates a handler, client or modifier plugin module with a go.mod pinned to the Go version and the dependencies of this binary.",
+		Run:     pluginInitFunc,
+		Args:    cobra.MaximumNArgs(1),
+		Example: "krakend plugin init -t modifier -m github.com/example/my-modifier ./my-modifier",
+	}
+
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Exports the configuration to other formats.",
+		Long:  "Exports the configuration to other formats.",
+	}
+
+	graphCmd = &cobra.Command{
+		Use:     "graph",
+		Short:   "Exports the dependency graph of endpoints, backends and hosts.",
+		Long:    "Exports the graph of the endpoints and async agents, the backends they connect to and the backend hosts as a Graphviz DOT or a Mermaid diagram.",
+		Run:     graphFunc,
+		Example: "krakend export graph -c krakend.json --format mermaid --group-by-host --mark auth/validator,qos/ratelimit/router",
+	}
+
+	statsCmd = &cobra.Command{
+		Use:     "stats",
+		Short:   "Shows metrics about the size and complexity of the configuration.",
+		Long:    "Shows the endpoints by method, the backends per endpoint, the backend hosts, the usage of the extra_config namespaces, the endpoints without authentication, the longest timeouts and the amplification factors. The thresholds make the command fail when exceeded.",
+		Run:     statsFunc,
+		Example: "krakend stats -c krakend.json --max-backends 5 --require-auth",
+	}
+
+	fmtCmd = &cobra.Command{
+		Use:     "fmt",
+		Short:   "Formats the configuration file in a canonical form.",
+		Long:    "Rewrites the JSON, YAML or TOML configuration file with its keys in the order of the JSON schema and a consistent indentation. The comments of the YAML files are kept, and the TOML files with comments are refused, as they cannot be kept. The --check and --diff modes do not modify the file.",
+		Run:     fmtFunc,
+		Example: "krakend fmt -c krakend.json --check --diff",
+	}
+
+	convertCmd = &cobra.Command{
+		Use:     "convert",
+		Short:   "Converts the configuration file between JSON, YAML and TOML.",
+		Long:    "Converts the configuration file between JSON, YAML and TOML keeping the order of the keys and the numbers, and verifies the result parses to the same configuration.",
+		Run:     convertFunc,
+		Example: "krakend convert -c krakend.json --to yaml -o krakend.yaml",
+	}
+
+	lspCmd = &cobra.Command{
+		Use:     "lsp",
+		Short:   "Runs a language server for the configuration files.",
+		Long:    "Runs a Language Server Protocol server over stdio publishing the problems found by check, the lint against the embedded schema and audit on every change, with completion and docs of the extra_config namespaces and navigation from the backends to their hosts. The unsaved buffers are parsed as plain configuration files, without the flexible configuration nor the parser of the binary, so its custom settings do not apply to them; the templates of the flexible configuration are checked once saved.",
+		Run:     lspFunc,
+		Example: "krakend lsp -r rules.yaml -s CRITICAL,HIGH",
 	}
 
 	versionCmd = &cobra.Command{
@@ -82,12 +190,29 @@ var (
 		Example: "krakend version",
 	}
 
+	describeCmd = &cobra.Command{
+		Use:     "describe",
+		Short:   "Shows the dependencies of the KrakenD binary as JSON.",
+		Long:    "Shows the KrakenD and Go versions, the glibc version, the dependencies and the build settings of the binary as JSON, so plugins can be checked against it with check-plugin --host.",
+		Run:     describeFunc,
+		Example: "krakend version describe > descriptor.json",
+	}
+
 	auditCmd = &cobra.Command{
-		Use:     "audit",
+		Use:     "audit [config files or globs...]",
 		Short:   "Audits a KrakenD configuration.",
-		Long:    "Audits a KrakenD configuration.",
+		Long:    "Audits a KrakenD configuration.\nPass several files and globs as arguments to audit all of them in a single report grouped by file",
 		Run:     auditFunc,
-		Example: "krakend audit -i 1.1.1,1.1.2 -s CRITICAL -c krakend.json",
+		Example: "krakend audit -i 1.1.1,1.1.2 -s CRITICAL -c krakend.json\nkrakend audit --fail-on HIGH 'gateways/*/krakend.json'",
+	}
+
+	auditExplainCmd = &cobra.Command{
+		Use:     "explain RULE_ID...",
+		Short:   "Explains audit rules and how to fix them.",
+		Long:    "Shows the rationale and the documentation of the audit rules, with the configuration snippets fixing them.\nWhen a configuration is passed with --config, the snippets are generated for its endpoints and backends",
+		Run:     auditExplainFunc,
+		Args:    cobra.MinimumNArgs(1),
+		Example: "krakend audit explain 3.1.2 -c krakend.json",
 	}
 )
 
@@ -108,27 +233,85 @@ func init() {
 	lintCustomSchemaFlag := StringFlagBuilder(&lintCustomSchemaPath, "lint-schema", "s", lintCustomSchemaPath, "Lint against a custom schema path or URL")
 	lintNoNetworkFlag := BoolFlagBuilder(&lintNoNetwork, "lint-no-network", "n", lintNoNetwork, "Lint against the builtin Krakend JSON schema, no network is required")
 	checkDebugFlag := CountFlagBuilder(&checkDebug, "debug", "d", "Information about how KrakenD is interpreting your configuration file")
-	CheckCommand = NewCommand(checkCmd, cfgFlag, checkDebugFlag, ginRoutesFlag, prefixFlag, lintCurrentSchemaFlag, lintCustomSchemaFlag, lintNoNetworkFlag)
+	redactKeysFlag := StringFlagBuilder(&checkRedactKeys, "redact-keys", "", checkRedactKeys, "Additional key patterns to mask in the dump (comma-separated, no spaces). The patterns starting with = only match the keys equal to them")
+	noRedactFlag := BoolFlagBuilder(&checkNoRedact, "no-redact", "", false, "Shows the values of the sensitive keys in the dump. Use it only for local debugging")
+	effectiveFlag := BoolFlagBuilder(&checkEffective, "effective", "", false, "Dumps the settings applied to every endpoint and backend and where they come from (implies --debug)")
+	filterPathFlag := StringFlagBuilder(&checkFilterPath, "filter-path", "", "", "Dumps only the endpoints (or async agents) matching the glob, or the regexp when prefixed with 're:'")
+	filterMethodFlag := StringFlagBuilder(&checkFilterMethod, "filter-method", "", "", "Dumps only the endpoints with these methods (comma-separated, no spaces)")
+	filterHostFlag := StringFlagBuilder(&checkFilterHost, "filter-host", "", "", "Dumps only the backends with a host matching the glob, or the regexp when prefixed with 're:'")
+	filterNamespaceFlag := StringFlagBuilder(&checkFilterNamespace, "filter-namespace", "", "", "Dumps only the endpoints, async agents and backends with this extra_config namespace")
+	inspectCertsFlag := BoolFlagBuilder(&checkInspectCerts, "inspect-certs", "", false, "Loads the TLS certificates and keys to show their details and report expired or mismatching ones")
+	CheckCommand = NewCommand(checkCmd, cfgFlag, checkDebugFlag, ginRoutesFlag, prefixFlag, lintCurrentSchemaFlag, lintCustomSchemaFlag, lintNoNetworkFlag, redactKeysFlag, noRedactFlag, effectiveFlag,
+		filterPathFlag, filterMethodFlag, filterHostFlag, filterNamespaceFlag, inspectCertsFlag)
 	CheckCommand.AddConstraint(MutuallyExclusive("lint", "lint-no-network", "lint-schema"))
+	CheckCommand.AddConstraint(MutuallyExclusive("redact-keys", "no-redact"))
 
 	portFlag := IntFlagBuilder(&port, "port", "p", 0, "Listening port for the http service")
 	RunCommand = NewCommand(runCmd, cfgFlag, debugFlag, portFlag)
 
 	goSumFlag := StringFlagBuilder(&goSum, "sum", "s", goSum, "Path to the go.sum file to analyze")
-	goVersionFlag := StringFlagBuilder(&goVersion, "go", "g", goVersion, "The version of the go compiler used for your plugin")
-	libcVersionFlag := StringFlagBuilder(&libcVersion, "libc", "l", "", "Version of the libc library used")
+	goVersionFlag := StringFlagBuilder(&goVersion, "go", "g", goVersion, "The version of the go compiler used for your plugin. Defaults to the one of the running binary, or to the one of --host when set")
+	libcVersionFlag := StringFlagBuilder(&libcVersion, "libc", "l", "", "Version of the libc library used (i.e. GLIBC-2.31). When --plugin is set, it is read from the binary. Defaults to the one of --host when set")
 	gogetFlag := BoolFlagBuilder(&gogetEnabled, "format", "f", false, "Shows fix commands to update your dependencies")
-	PluginCommand = NewCommand(pluginCmd, goSumFlag, goVersionFlag, libcVersionFlag, gogetFlag)
+	fixFlag := BoolFlagBuilder(&fixEnabled, "fix", "", false, "Rewrites the go.mod of your plugin to match the dependencies of the binary")
+	fixDryRunFlag := BoolFlagBuilder(&fixDryRun, "dry-run", "", false, "Shows the changes --fix would apply without writing them")
+	hostFlag := StringFlagBuilder(&hostDescriptor, "host", "", "", "Path to a descriptor generated with 'krakend version describe' to use instead of the running binary")
+	pluginBinaryFlag := StringFlagBuilder(&pluginBinary, "plugin", "p", "", "Path to the compiled plugin (.so) to check its Go version, dependencies and build settings instead of the go.sum")
+	PluginCommand = NewCommand(pluginCmd, goSumFlag, goVersionFlag, libcVersionFlag, gogetFlag, fixFlag, fixDryRunFlag, hostFlag, pluginBinaryFlag)
+	PluginCommand.AddConstraint(MutuallyExclusive("format", "fix"))
 
 	rulesToExcludeFlag := StringFlagBuilder(&rulesToExclude, "ignore", "i", rulesToExclude, "List of rules to ignore (comma-separated, no spaces)")
-	severitiesToIncludeFlag := StringFlagBuilder(&severitiesToInclude, "severity", "s", severitiesToInclude, "List of severities to include (comma-separated, no spaces)")
-	pathToRulesToExcludeFlag := StringFlagBuilder(&rulesToExcludePath, "ignore-file", "I", rulesToExcludePath, "Path to a text-plain file containing the list of rules to exclude")
-	formatFlag := StringFlagBuilder(&formatTmpl, "format", "f", formatTmpl, "Inline go template to render the results")
-	AuditCommand = NewCommand(auditCmd, cfgFlag, rulesToExcludeFlag, severitiesToIncludeFlag, pathToRulesToExcludeFlag, formatFlag)
+	severitiesToIncludeFlag := StringFlagBuilder(&severitiesToInclude, "severity", "s", severitiesToInclude, "List of severities to show (comma-separated, no spaces). The summary, the score and --fail-on consider all of them")
+	pathToRulesToExcludeFlag := StringFlagBuilder(&rulesToExcludePath, "ignore-file", "I", rulesToExcludePath, "Path to a text-plain file containing the list of rules to exclude, or to a JSON or YAML file with scoped suppressions")
+	formatFlag := StringFlagBuilder(&formatTmpl, "format", "f", formatTmpl, "Go template to render the results: inline, @path/to/file.tmpl or one of the built-in templates (table, markdown, html)")
+	auditRulesFlag := StringFlagBuilder(&auditRulesPath, "rules", "r", "", "Path to a JSON or YAML file, or a folder of them, with custom rules to evaluate")
+	auditFailOnFlag := StringFlagBuilder(&auditFailOn, "fail-on", "", "", "Fails only when there are recommendations with this severity or higher, even if --severity hides them, exiting with 2 (LOW), 3 (MEDIUM), 4 (HIGH) or 5 (CRITICAL)")
+	auditOutputFileFlag := StringArrayFlagBuilder(&auditOutputFiles, "output-file", "o", nil, "File to write the report to, - for the standard output. Repeat it to write several reports: .json, .md and .html files get the report in that format, and the rest use --format")
+	auditHistoryFlag := StringFlagBuilder(&auditHistoryDir, "history", "", "", "Directory to store a snapshot of every run in, showing the changes since the previous one")
+	auditExplainFlag := BoolFlagBuilder(&auditExplain, "explain", "", false, "Explains the rules of the recommendations, with the configuration snippets fixing them")
+	AuditCommand = NewCommand(auditCmd, cfgFlag, rulesToExcludeFlag, severitiesToIncludeFlag, pathToRulesToExcludeFlag, formatFlag, auditRulesFlag, auditFailOnFlag, auditOutputFileFlag, auditHistoryFlag, auditExplainFlag)
+	AuditCommand.AddSubCommand(auditExplainCmd)
+
+	scaffoldTypeFlag := StringFlagBuilder(&scaffoldType, "type", "t", scaffoldType, "Type of plugin to generate: handler, client or modifier")
+	scaffoldNameFlag := StringFlagBuilder(&scaffoldName, "name", "", scaffoldName, "Name of the plugin (defaults to the folder name)")
+	scaffoldModuleFlag := StringFlagBuilder(&scaffoldModule, "module", "m", scaffoldModule, "Module path of the plugin (defaults to the plugin name)")
+	ScaffoldCommand = NewCommand(scaffoldCmd, scaffoldTypeFlag, scaffoldNameFlag, scaffoldModuleFlag)
+	ScaffoldCommand.AddSubCommand(scaffoldInitCmd)
+
+	graphFormatFlag := StringFlagBuilder(&graphFormat, "format", "f", graphFormat, "Format of the graph: dot or mermaid")
+	graphGroupByHostFlag := BoolFlagBuilder(&graphGroupByHost, "group-by-host", "", false, "Groups the backends by host")
+	graphCollapseFlag := BoolFlagBuilder(&graphCollapse, "collapse", "", false, "Merges the backends with the same method, URL pattern and hosts")
+	graphMarksFlag := StringFlagBuilder(&graphMarks, "mark", "m", graphMarks, "Highlights the nodes with any of these extra_config namespaces (comma-separated, no spaces)")
+	ExportCommand = NewCommand(exportCmd, cfgFlag, graphFormatFlag, graphGroupByHostFlag, graphCollapseFlag, graphMarksFlag)
+	ExportCommand.AddSubCommand(graphCmd)
+
+	statsOutputFlag := StringFlagBuilder(&statsOutput, "output", "o", statsOutput, "Output format: text or json")
+	statsMaxEndpointsFlag := IntFlagBuilder(&statsMaxEndpoints, "max-endpoints", "", 0, "Fails when the configuration has more endpoints (0 disables the check)")
+	statsMaxBackendsFlag := IntFlagBuilder(&statsMaxBackends, "max-backends", "", 0, "Fails when an endpoint has more backends (0 disables the check)")
+	statsAmplificationFlag := IntFlagBuilder(&statsAmplification, "max-amplification", "", 0, "Fails when a request to an endpoint triggers more backend requests (0 disables the check)")
+	statsMaxTimeoutFlag := DurationFlagBuilder(&statsMaxTimeout, "max-timeout", "", 0, "Fails when an endpoint has a longer timeout (0 disables the check)")
+	statsRequireAuthFlag := BoolFlagBuilder(&statsRequireAuth, "require-auth", "", false, "Fails when an endpoint has no authentication namespace")
+	StatsCommand = NewCommand(statsCmd, cfgFlag, statsOutputFlag, statsMaxEndpointsFlag, statsMaxBackendsFlag, statsAmplificationFlag, statsMaxTimeoutFlag, statsRequireAuthFlag)
+
+	fmtIndentFlag := StringFlagBuilder(&fmtIndent, "indent", "i", fmtIndent, "Indentation of the formatted file")
+	fmtCheckFlag := BoolFlagBuilder(&fmtCheck, "check", "", false, "Fails if the file is not formatted, without modifying it")
+	fmtDiffFlag := BoolFlagBuilder(&fmtDiff, "diff", "", false, "Shows the changes as a unified diff, without modifying the file")
+	fmtSortEndpointsFlag := BoolFlagBuilder(&fmtSortEndpoints, "sort-endpoints", "", false, "Sorts the endpoints by path and method")
+	FmtCommand = NewCommand(fmtCmd, cfgFlag, fmtIndentFlag, fmtCheckFlag, fmtDiffFlag, fmtSortEndpointsFlag)
+
+	convertToFlag := StringFlagBuilder(&convertTo, "to", "t", "", "Output format: json, yaml or toml. Defaults to the extension of --output")
+	convertOutputFlag := StringFlagBuilder(&convertOutput, "output", "o", "", "Path of the converted file. The result is printed when empty")
+	convertIndentFlag := StringFlagBuilder(&convertIndent, "indent", "i", convertIndent, "Indentation of the converted file")
+	convertNoVerifyFlag := BoolFlagBuilder(&convertNoVerify, "no-verify", "", false, "Skips the check of the converted file against the original configuration")
+	ConvertCommand = NewCommand(convertCmd, cfgFlag, convertToFlag, convertOutputFlag, convertIndentFlag, convertNoVerifyFlag)
+
+	LspCommand = NewCommand(lspCmd, rulesToExcludeFlag, severitiesToIncludeFlag, pathToRulesToExcludeFlag, auditRulesFlag)
 
-	VersionCommand = NewCommand(versionCmd)
+	versionOutputFlag := StringFlagBuilder(&versionOutput, "output", "o", versionOutput, "Output format: text or json")
+	VersionCommand = NewCommand(versionCmd, versionOutputFlag)
+	VersionCommand.AddSubCommand(describeCmd)
 
-	DefaultRoot = NewRoot(RootCommand, CheckCommand, RunCommand, PluginCommand, VersionCommand, AuditCommand)
+	DefaultRoot = NewRoot(RootCommand, CheckCommand, RunCommand, PluginCommand, ScaffoldCommand, VersionCommand, AuditCommand, ExportCommand, StatsCommand, FmtCommand, ConvertCommand, LspCommand)
 }
 
 const encodedLogo = "IOKVk+KWhOKWiCAgICAgICAgICAgICAgICAgICAgICAgICAg4paE4paE4paMICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgIOKVk+KWiOKWiOKWiOKWiOKWiOKWiOKWhMK1ICAK4paQ4paI4paI4paIICDiloTilojilojilojilajilpDilojilojilojiloTilojilohI4pWX4paI4paI4paI4paI4paI4paI4paEICDilZHilojilojilowgLOKWhOKWiOKWiOKWiOKVqCDiloTilojilojilojilojilojilojiloQgIOKWk+KWiOKWiOKWjOKWiOKWiOKWiOKWiOKWiOKWhCAg4paI4paI4paI4paA4pWZ4pWZ4paA4paA4paI4paI4paI4pWVCuKWkOKWiOKWiOKWiOKWhOKWiOKWiOKWiOKWgCAg4paQ4paI4paI4paI4paI4paI4paAIuKVmeKWgOKWgCLilZniloDilojilojilogg4pWR4paI4paI4paI4paE4paI4paI4paI4pSYICDilojilojilojiloAiIuKWgOKWiOKWiOKWiCDilojilojilojilojiloDilZniloDilojilojilohIIOKWiOKWiOKWiCAgICAg4pWZ4paI4paI4paICuKWkOKWiOKWiOKWiOKWiOKWiOKWiOKWjCAgIOKWkOKWiOKWiOKWiOKMkCAgLOKWhOKWiOKWiOKWiOKWiOKWiOKWiOKWiOKWiE3ilZHilojilojilojilojilojilojiloQgIOKVkeKWiOKWiOKWiOKWiOKWiOKWiOKWiOKWiOKWiOKWiE3ilojilojilojilowgICDilojilojilohIIOKWiOKWiOKWiCAgICAgLOKWiOKWiOKWiArilpDilojilojilojilajiloDilojilojilojCtSDilpDilojilojiloggICDilojilojilojilowgICzilojilojilohN4pWR4paI4paI4paI4pWZ4paA4paI4paI4paIICDilojilojilojiloRgYGDiloTiloRgIOKWiOKWiOKWiOKWjCAgIOKWiOKWiOKWiEgg4paI4paI4paILCws4pWT4paE4paI4paI4paI4paACuKWkOKWiOKWiOKWiCAg4pWZ4paI4paI4paI4paE4paQ4paI4paI4paIICAg4pWZ4paI4paI4paI4paI4paI4paI4paI4paI4paITeKVkeKWiOKWiOKWjCAg4pWZ4paI4paI4paI4paEYOKWgOKWiOKWiOKWiOKWiOKWiOKWiOKWiOKVqCDilojilojilojilowgICDilojilojilohIIOKWiOKWiOKWiOKWiOKWiOKWiOKWiOKWiOKWiOKWgCAgCiAgICAgICAgICAgICAgICAgICAgIGBgICAgICAgICAgICAgICAgICAgICAgYCdgICAgICAgICAgICAgICAgICAgICAgICAgICAgIAo="
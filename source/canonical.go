@@ -0,0 +1,327 @@
+package source
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/luraproject/lura/v2/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// schemaKey is the key of the JSON schema reference in the configuration files
+const schemaKey = "$schema"
+
+// trailingKeys are moved to the end of their objects, as they usually hold the
+// biggest values
+var trailingKeys = []string{"extra_config", "backend", "endpoints", "async_agent"}
+
+// Layout is the order of the keys of the configuration files
+type Layout struct {
+	root *layout
+}
+
+// DefaultLayout orders the keys of the known sections as in the lura config
+// definitions, for the binaries without a JSON schema
+var DefaultLayout = &Layout{root: serviceLayout}
+
+// Canonical returns a copy of the configuration with its keys in the DefaultLayout
+func Canonical(v interface{}, sortEndpoints bool) interface{} {
+	return DefaultLayout.Canonical(v, sortEndpoints)
+}
+
+// Canonical returns a copy of the configuration with the keys of the known sections
+// ordered by the layout and the rest of keys sorted alphabetically. The $schema and
+// version keys go first, and the extra_config, backend, endpoints and async_agent keys
+// go last. When sortEndpoints is set, the endpoints are also sorted by path and method.
+func (l *Layout) Canonical(v interface{}, sortEndpoints bool) interface{} {
+	obj, ok := v.(Object)
+	if !ok {
+		return v
+	}
+	res := canonical(obj, l.root)
+	if !sortEndpoints {
+		return res
+	}
+
+	for i, m := range res {
+		if m.Key != "endpoints" {
+			continue
+		}
+		endpoints, ok := m.Value.([]interface{})
+		if !ok {
+			break
+		}
+		sorted := append([]interface{}{}, endpoints...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return endpointLess(endpointID(sorted[i]), endpointID(sorted[j]))
+		})
+		res[i].Value = sorted
+	}
+	return res
+}
+
+func endpointLess(a, b [2]string) bool {
+	if a[0] != b[0] {
+		return a[0] < b[0]
+	}
+	return a[1] < b[1]
+}
+
+func endpointID(v interface{}) [2]string {
+	obj, _ := v.(Object)
+	path, _ := obj.Get("endpoint")
+	method, _ := obj.Get("method")
+	p, _ := path.(string)
+	m, _ := method.(string)
+	return normalizedEndpointID(p, m)
+}
+
+func normalizedEndpointID(path, method string) [2]string {
+	if method == "" {
+		method = "GET"
+	}
+	return [2]string{path, strings.ToUpper(method)}
+}
+
+// layout is the known order of the keys of an object and the layouts of its children
+type layout struct {
+	keys     []string
+	children map[string]*layout
+	// items is set when the children are arrays of objects
+	items map[string]*layout
+}
+
+var serviceLayout = withLeadingKeys(newLayout(reflect.TypeOf(config.ServiceConfig{})))
+
+// withLeadingKeys moves the $schema and version keys to the beginning of the layout
+func withLeadingKeys(l *layout) *layout {
+	keys := []string{schemaKey, "version"}
+	for _, k := range l.keys {
+		if k != schemaKey && k != "version" {
+			keys = append(keys, k)
+		}
+	}
+	l.keys = keys
+	return l
+}
+
+// newLayout builds the layout of the struct from its mapstructure tags
+func newLayout(t reflect.Type) *layout {
+	l := &layout{
+		children: map[string]*layout{},
+		items:    map[string]*layout{},
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		l.keys = append(l.keys, key)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && ft.PkgPath() == t.PkgPath():
+			l.children[key] = newLayout(ft)
+		case ft.Kind() == reflect.Slice:
+			et := ft.Elem()
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct && et.PkgPath() == t.PkgPath() {
+				l.items[key] = newLayout(et)
+			}
+		}
+	}
+	return l
+}
+
+func (l *layout) rank(key string) int {
+	for i, k := range trailingKeys {
+		if k == key {
+			return len(l.keys) + 1 + i
+		}
+	}
+	for i, k := range l.keys {
+		if k == key {
+			return i
+		}
+	}
+	return len(l.keys)
+}
+
+// less reports if the key goes before the other one in the objects with the layout
+func (l *layout) less(a, b string) bool {
+	if l == nil {
+		return a < b
+	}
+	ra, rb := l.rank(a), l.rank(b)
+	if ra != rb {
+		return ra < rb
+	}
+	return a < b
+}
+
+// child returns the layouts of the object and of the items of the array at the key
+func (l *layout) child(key string) (*layout, *layout) {
+	if l == nil {
+		return nil, nil
+	}
+	return l.children[key], l.items[key]
+}
+
+// canonical orders the keys of the object following the layout. The objects without
+// layout, like the extra_config ones, get their keys sorted alphabetically.
+func canonical(obj Object, l *layout) Object {
+	res := make(Object, len(obj))
+	copy(res, obj)
+	sort.SliceStable(res, func(i, j int) bool {
+		return l.less(res[i].Key, res[j].Key)
+	})
+
+	for i, m := range res {
+		child, items := l.child(m.Key)
+		switch t := m.Value.(type) {
+		case Object:
+			res[i].Value = canonical(t, child)
+		case []interface{}:
+			arr := make([]interface{}, len(t))
+			for j, e := range t {
+				if o, ok := e.(Object); ok {
+					arr[j] = canonical(o, items)
+					continue
+				}
+				arr[j] = e
+			}
+			res[i].Value = arr
+		}
+	}
+	return res
+}
+
+// CanonicalYAML returns the YAML document with its keys ordered by the layout, like
+// Canonical, keeping the comments of the document
+func (l *Layout) CanonicalYAML(data []byte, sortEndpoints bool, indent string) ([]byte, error) {
+	if _, err := decodeYAML(data); err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	root := doc.Content[0]
+	// the comment before the first key is the header of the file, so it stays on top
+	var header string
+	if root.Kind == yaml.MappingNode && len(root.Content) > 0 {
+		header, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	canonicalNode(root, l.root)
+	if header != "" {
+		root.Content[0].HeadComment = strings.TrimSpace(header + "\n" + root.Content[0].HeadComment)
+	}
+	if sortEndpoints && root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "endpoints" || root.Content[i+1].Kind != yaml.SequenceNode {
+				continue
+			}
+			endpoints := root.Content[i+1].Content
+			sort.SliceStable(endpoints, func(i, j int) bool {
+				return endpointLess(endpointNodeID(endpoints[i]), endpointNodeID(endpoints[j]))
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(max(len(indent), 2))
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// canonicalNode orders the keys of the mapping nodes following the layout, moving
+// the comments with their keys, and drops the flow and quoting styles so they are
+// rendered the same way
+func canonicalNode(n *yaml.Node, l *layout) {
+	n.Style &^= yaml.FlowStyle | yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+	switch n.Kind {
+	case yaml.MappingNode:
+		pairs := make([][2]*yaml.Node, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{n.Content[i], n.Content[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return l.less(pairs[i][0].Value, pairs[j][0].Value)
+		})
+		n.Content = n.Content[:0]
+		for _, p := range pairs {
+			n.Content = append(n.Content, p[0], p[1])
+			canonicalNode(p[0], nil)
+			child, items := l.child(p[0].Value)
+			if p[1].Kind == yaml.SequenceNode {
+				p[1].Style &^= yaml.FlowStyle
+				for _, e := range p[1].Content {
+					canonicalNode(e, items)
+				}
+				continue
+			}
+			canonicalNode(p[1], child)
+		}
+	case yaml.SequenceNode:
+		for _, e := range n.Content {
+			canonicalNode(e, nil)
+		}
+	}
+}
+
+func endpointNodeID(n *yaml.Node) [2]string {
+	var path, method string
+	for i := 0; n.Kind == yaml.MappingNode && i+1 < len(n.Content); i += 2 {
+		switch n.Content[i].Value {
+		case "endpoint":
+			path = n.Content[i+1].Value
+		case "method":
+			method = n.Content[i+1].Value
+		}
+	}
+	return normalizedEndpointID(path, method)
+}
+
+// HasTOMLComments reports if the TOML document has comments, which are lost when
+// decoding it
+func HasTOMLComments(data []byte) bool {
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '#':
+			return true
+		case '"', '\'':
+			quote := data[i]
+			delim := []byte{quote}
+			if bytes.HasPrefix(data[i:], []byte{quote, quote, quote}) {
+				delim = []byte{quote, quote, quote}
+			}
+			i += len(delim)
+			for i < len(data) && !bytes.HasPrefix(data[i:], delim) {
+				// the literal strings have no escapes
+				if data[i] == '\\' && quote == '"' {
+					i++
+				}
+				i++
+			}
+			i += len(delim) - 1
+		}
+	}
+	return false
+}
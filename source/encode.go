@@ -0,0 +1,292 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Encode writes the value in the received format. The indent is used for the nested
+// JSON elements; YAML uses as many spaces as characters in the indent (at least 2),
+// and TOML does not indent.
+func Encode(v interface{}, format, indent string) ([]byte, error) {
+	switch format {
+	case JSON:
+		return encodeJSON(v, indent)
+	case YAML:
+		return encodeYAML(v, indent)
+	case TOML:
+		return encodeTOML(v)
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+func encodeJSON(v interface{}, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v, indent, ""); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}, indent, prefix string) error {
+	switch t := v.(type) {
+	case Object:
+		if len(t) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i, m := range t {
+			buf.WriteString(prefix + indent)
+			writeJSONString(buf, m.Key)
+			buf.WriteString(": ")
+			if err := writeJSON(buf, m.Value, indent, prefix+indent); err != nil {
+				return err
+			}
+			if i < len(t)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(prefix + "}")
+	case []interface{}:
+		if len(t) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, e := range t {
+			buf.WriteString(prefix + indent)
+			if err := writeJSON(buf, e, indent, prefix+indent); err != nil {
+				return err
+			}
+			if i < len(t)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(prefix + "]")
+	case string:
+		writeJSONString(buf, t)
+	case json.Number:
+		buf.WriteString(t.String())
+	case bool:
+		fmt.Fprintf(buf, "%t", t)
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("unsupported value of type %T", v)
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	// drop the newline added by the encoder
+	buf.Truncate(buf.Len() - 1)
+}
+
+func encodeYAML(v interface{}, indent string) ([]byte, error) {
+	n, err := toYAMLNode(v)
+	if err != nil {
+		return nil, err
+	}
+	spaces := len(indent)
+	if spaces < 2 {
+		spaces = 2
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(spaces)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toYAMLNode(v interface{}) (*yaml.Node, error) {
+	switch t := v.(type) {
+	case Object:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, m := range t {
+			val, err := toYAMLNode(m.Value)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.Key}, val)
+		}
+		return n, nil
+	case []interface{}:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, e := range t {
+			val, err := toYAMLNode(e)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, val)
+		}
+		return n, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprintf("%t", t)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", v)
+	}
+}
+
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func encodeTOML(v interface{}) ([]byte, error) {
+	obj, ok := v.(Object)
+	if !ok {
+		return nil, errors.New("the TOML documents must be objects")
+	}
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, nil, obj); err != nil {
+		return nil, err
+	}
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+// writeTOMLTable writes the key/value pairs of the table, followed by its sub-tables
+// and arrays of tables, as TOML requires
+func writeTOMLTable(buf *bytes.Buffer, path []string, obj Object) error {
+	for _, m := range obj {
+		if isTOMLTable(m.Value) || isTOMLTableArray(m.Value) {
+			continue
+		}
+		buf.WriteString(tomlKey(m.Key) + " = ")
+		if err := writeTOMLInline(buf, m.Value); err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(append(path, m.Key), "."), err)
+		}
+		buf.WriteByte('\n')
+	}
+
+	for _, m := range obj {
+		p := append(append([]string{}, path...), m.Key)
+		switch {
+		case isTOMLTable(m.Value):
+			// the header of the tables holding only sub-tables is implicit
+			if !onlyTOMLTables(m.Value.(Object)) {
+				fmt.Fprintf(buf, "\n[%s]\n", tomlPath(p))
+			}
+			if err := writeTOMLTable(buf, p, m.Value.(Object)); err != nil {
+				return err
+			}
+		case isTOMLTableArray(m.Value):
+			for _, e := range m.Value.([]interface{}) {
+				fmt.Fprintf(buf, "\n[[%s]]\n", tomlPath(p))
+				if err := writeTOMLTable(buf, p, e.(Object)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isTOMLTable(v interface{}) bool {
+	_, ok := v.(Object)
+	return ok
+}
+
+func onlyTOMLTables(obj Object) bool {
+	for _, m := range obj {
+		if !isTOMLTable(m.Value) {
+			return false
+		}
+	}
+	return len(obj) > 0
+}
+
+func isTOMLTableArray(v interface{}) bool {
+	arr, ok := v.([]interface{})
+	if !ok || len(arr) == 0 {
+		return false
+	}
+	for _, e := range arr {
+		if _, ok := e.(Object); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func writeTOMLInline(buf *bytes.Buffer, v interface{}) error {
+	switch t := v.(type) {
+	case Object:
+		buf.WriteString("{")
+		for i, m := range t {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(" " + tomlKey(m.Key) + " = ")
+			if err := writeTOMLInline(buf, m.Value); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(" }")
+	case []interface{}:
+		buf.WriteString("[")
+		for i, e := range t {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeTOMLInline(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+	case string:
+		writeJSONString(buf, t)
+	case json.Number:
+		buf.WriteString(t.String())
+	case bool:
+		fmt.Fprintf(buf, "%t", t)
+	case nil:
+		return errors.New("TOML does not support null values")
+	default:
+		return fmt.Errorf("unsupported value of type %T", v)
+	}
+	return nil
+}
+
+func tomlKey(k string) string {
+	if bareTOMLKey.MatchString(k) {
+		return k
+	}
+	var buf bytes.Buffer
+	writeJSONString(&buf, k)
+	return buf.String()
+}
+
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, k := range path {
+		keys[i] = tomlKey(k)
+	}
+	return strings.Join(keys, ".")
+}
@@ -0,0 +1,296 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// Position is the location of a value in a source file. Lines and columns start at
+// 1, and a zero line refers to the whole file.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Positions are the positions of the values of a document by their JSON pointer. The
+// members of the objects are located at their keys.
+type Positions map[string]Position
+
+// Pointer returns the JSON pointer of the path of keys and indexes
+func Pointer(path ...string) string {
+	var sb strings.Builder
+	for _, p := range path {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(p))
+	}
+	return sb.String()
+}
+
+// Lookup returns the position of the value at the pointer or, when it is not in the
+// source, the position of its closest ancestor
+func (p Positions) Lookup(pointer string) Position {
+	for {
+		if pos, ok := p[pointer]; ok {
+			return pos
+		}
+		i := strings.LastIndexByte(pointer, '/')
+		if i < 0 {
+			return p[""]
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// Span is a fragment of a document copied from another file, like the partials
+// included by the flexible configuration
+type Span struct {
+	File string
+	// Start and End are the positions of the first and the last characters of the
+	// fragment in the document
+	Start, End Position
+}
+
+// FindSpans returns the fragments of the document that are a verbatim copy of the
+// content of the files
+func FindSpans(data []byte, files map[string][]byte) []Span {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := lineOffsets(data)
+	var spans []Span
+	for _, name := range names {
+		content := bytes.TrimRight(files[name], "\n")
+		if len(bytes.TrimSpace(content)) == 0 {
+			continue
+		}
+		for offset := 0; ; {
+			i := bytes.Index(data[offset:], content)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			end := start + len(content) - 1
+			spans = append(spans, Span{File: name, Start: position(data, lines, start), End: position(data, lines, end)})
+			offset = end + 1
+		}
+	}
+	return spans
+}
+
+// Remap moves the positions found inside the spans to the files they were copied from
+func (p Positions) Remap(spans []Span) {
+	for ptr, pos := range p {
+		for _, s := range spans {
+			if !s.contains(pos) {
+				continue
+			}
+			column := pos.Column
+			if pos.Line == s.Start.Line {
+				column -= s.Start.Column - 1
+			}
+			p[ptr] = Position{File: s.File, Line: pos.Line - s.Start.Line + 1, Column: column}
+			break
+		}
+	}
+}
+
+func (s Span) contains(p Position) bool {
+	after := p.Line > s.Start.Line || (p.Line == s.Start.Line && p.Column >= s.Start.Column)
+	before := p.Line < s.End.Line || (p.Line == s.End.Line && p.Column <= s.End.Column)
+	return after && before
+}
+
+// Locate returns the positions of the values of the document in the received format,
+// labeled with the name of the file
+func Locate(data []byte, format, file string) (Positions, error) {
+	p := Positions{"": {File: file, Line: 1, Column: 1}}
+	var err error
+	switch format {
+	case JSON:
+		err = locateJSON(data, p)
+	case YAML:
+		err = locateYAML(data, p)
+	case TOML:
+		err = locateTOML(data, p)
+	default:
+		err = fmt.Errorf("unknown format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for ptr, pos := range p {
+		pos.File = file
+		p[ptr] = pos
+	}
+	return p, nil
+}
+
+type jsonLocator struct {
+	data  []byte
+	lines []int
+	dec   *json.Decoder
+	res   Positions
+}
+
+func locateJSON(data []byte, res Positions) error {
+	l := &jsonLocator{data: data, lines: lineOffsets(data), dec: json.NewDecoder(bytes.NewReader(data)), res: res}
+	l.dec.UseNumber()
+	t, start, err := l.next()
+	if err != nil {
+		return err
+	}
+	res[""] = position(data, l.lines, start)
+	if err := l.value("", t); err != nil {
+		return err
+	}
+	if _, err := l.dec.Token(); err != io.EOF {
+		return errors.New("invalid JSON: unexpected content after the top-level value")
+	}
+	return nil
+}
+
+// next returns the next token and the offset where it starts
+func (l *jsonLocator) next() (json.Token, int, error) {
+	start := int(l.dec.InputOffset())
+	for start < len(l.data) && strings.IndexByte(" \t\r\n,:", l.data[start]) >= 0 {
+		start++
+	}
+	t, err := l.dec.Token()
+	return t, start, err
+}
+
+func (l *jsonLocator) value(ptr string, t json.Token) error {
+	switch t {
+	case json.Delim('{'):
+		for l.dec.More() {
+			k, start, err := l.next()
+			if err != nil {
+				return err
+			}
+			child := ptr + Pointer(k.(string))
+			l.res[child] = position(l.data, l.lines, start)
+			v, _, err := l.next()
+			if err != nil {
+				return err
+			}
+			if err := l.value(child, v); err != nil {
+				return err
+			}
+		}
+		_, err := l.dec.Token()
+		return err
+	case json.Delim('['):
+		for i := 0; l.dec.More(); i++ {
+			v, start, err := l.next()
+			if err != nil {
+				return err
+			}
+			child := ptr + Pointer(strconv.Itoa(i))
+			l.res[child] = position(l.data, l.lines, start)
+			if err := l.value(child, v); err != nil {
+				return err
+			}
+		}
+		_, err := l.dec.Token()
+		return err
+	default:
+		return nil
+	}
+}
+
+func locateYAML(data []byte, res Positions) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) > 0 {
+		locateYAMLNode(doc.Content[0], "", res)
+	}
+	return nil
+}
+
+func locateYAMLNode(n *yaml.Node, ptr string, res Positions) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		locateYAMLNode(n.Alias, ptr, res)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i]
+			child := ptr + Pointer(k.Value)
+			res[child] = Position{Line: k.Line, Column: k.Column}
+			locateYAMLNode(n.Content[i+1], child, res)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			child := ptr + Pointer(strconv.Itoa(i))
+			res[child] = Position{Line: c.Line, Column: c.Column}
+			locateYAMLNode(c, child, res)
+		}
+	}
+}
+
+// locateTOML locates the keys and the tables of the document. The elements of the
+// inline arrays have no position of their own.
+func locateTOML(data []byte, res Positions) error {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return err
+	}
+	locateTOMLTree(tree, "", res)
+	return nil
+}
+
+func locateTOMLTree(tree *toml.Tree, ptr string, res Positions) {
+	for _, k := range tree.Keys() {
+		child := ptr + Pointer(k)
+		pos := tomlPosition(tree, k)
+		res[child] = Position{Line: pos.Line, Column: pos.Col}
+		switch t := tree.GetPath([]string{k}).(type) {
+		case *toml.Tree:
+			locateTOMLTree(t, child, res)
+		case []*toml.Tree:
+			for i, sub := range t {
+				item := child + Pointer(strconv.Itoa(i))
+				res[item] = Position{Line: sub.Position().Line, Column: sub.Position().Col}
+				locateTOMLTree(sub, item, res)
+			}
+		}
+	}
+}
+
+// lineOffsets returns the offsets where the lines of the content start
+func lineOffsets(data []byte) []int {
+	lines := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+func position(data []byte, lines []int, offset int) Position {
+	line := sort.Search(len(lines), func(i int) bool { return lines[i] > offset }) - 1
+	return Position{Line: line + 1, Column: utf8.RuneCount(data[lines[line]:offset]) + 1}
+}
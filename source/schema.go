@@ -0,0 +1,165 @@
+package source
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaLayout builds the layout of the configuration files from the order of the
+// properties in their JSON schema. The schema can reference its parts with JSON
+// pointers or with the $id of the bundled schemas, and the properties of the allOf,
+// anyOf and oneOf subschemas are merged.
+func SchemaLayout(schema []byte) (*Layout, error) {
+	v, err := Decode(schema, JSON)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := v.(Object)
+	if !ok {
+		return nil, fmt.Errorf("the schema is not an object")
+	}
+	b := &layoutBuilder{root: root, ids: map[string]Object{}, done: map[*Member]*layout{}}
+	b.index(root)
+	l := b.build(root)
+	if l == nil {
+		l = &layout{children: map[string]*layout{}, items: map[string]*layout{}}
+	}
+	return &Layout{root: withLeadingKeys(l)}, nil
+}
+
+type layoutBuilder struct {
+	root Object
+	ids  map[string]Object
+	// done memoizes the layouts of the schemas, so the recursive ones end
+	done map[*Member]*layout
+}
+
+// index collects the schemas declaring an $id
+func (b *layoutBuilder) index(v interface{}) {
+	switch t := v.(type) {
+	case Object:
+		if id, ok := t.Get("$id"); ok {
+			if s, ok := id.(string); ok {
+				b.ids[s] = t
+			}
+		}
+		for _, m := range t {
+			b.index(m.Value)
+		}
+	case []interface{}:
+		for _, e := range t {
+			b.index(e)
+		}
+	}
+}
+
+// resolve follows the $ref of the schema, if any
+func (b *layoutBuilder) resolve(v interface{}) Object {
+	obj, _ := v.(Object)
+	for i := 0; obj != nil && i < 10; i++ {
+		ref, ok := obj.Get("$ref")
+		if !ok {
+			return obj
+		}
+		s, _ := ref.(string)
+		obj = b.lookup(s)
+	}
+	return obj
+}
+
+func (b *layoutBuilder) lookup(ref string) Object {
+	base, fragment, _ := strings.Cut(ref, "#")
+	var doc interface{} = b.root
+	if base != "" {
+		s, ok := b.ids[base]
+		if !ok {
+			// relative references of the bundled schemas, resolved against their $id
+			ids := make([]string, 0, len(b.ids))
+			for id := range b.ids {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				if strings.HasSuffix(id, "/"+strings.TrimPrefix(base, "./")) {
+					s = b.ids[id]
+					break
+				}
+			}
+		}
+		if s == nil {
+			return nil
+		}
+		doc = s
+	}
+	for _, p := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		if p == "" {
+			continue
+		}
+		obj, ok := doc.(Object)
+		if !ok {
+			return nil
+		}
+		doc, _ = obj.Get(strings.NewReplacer("~1", "/", "~0", "~").Replace(p))
+	}
+	obj, _ := doc.(Object)
+	return obj
+}
+
+// properties returns the properties of the schema and of its subschemas, in order
+func (b *layoutBuilder) properties(obj Object, depth int) []Member {
+	if obj == nil || depth > 10 {
+		return nil
+	}
+	var res []Member
+	if props, ok := obj.Get("properties"); ok {
+		if p, ok := props.(Object); ok {
+			res = append(res, p...)
+		}
+	}
+	for _, k := range []string{"allOf", "anyOf", "oneOf"} {
+		v, _ := obj.Get(k)
+		subschemas, _ := v.([]interface{})
+		for _, s := range subschemas {
+			res = append(res, b.properties(b.resolve(s), depth+1)...)
+		}
+	}
+	return res
+}
+
+// build returns the layout of the objects described by the schema, or nil if the
+// schema has no properties
+func (b *layoutBuilder) build(v interface{}) *layout {
+	obj := b.resolve(v)
+	if len(obj) == 0 {
+		return nil
+	}
+	if l, ok := b.done[&obj[0]]; ok {
+		return l
+	}
+	props := b.properties(obj, 0)
+	if len(props) == 0 {
+		b.done[&obj[0]] = nil
+		return nil
+	}
+
+	l := &layout{children: map[string]*layout{}, items: map[string]*layout{}}
+	b.done[&obj[0]] = l
+	seen := map[string]bool{}
+	for _, p := range props {
+		if !seen[p.Key] {
+			seen[p.Key] = true
+			l.keys = append(l.keys, p.Key)
+		}
+		s := b.resolve(p.Value)
+		if c := b.build(s); c != nil && l.children[p.Key] == nil {
+			l.children[p.Key] = c
+		}
+		if items, ok := s.Get("items"); ok {
+			if c := b.build(items); c != nil && l.items[p.Key] == nil {
+				l.items[p.Key] = c
+			}
+		}
+	}
+	return l
+}
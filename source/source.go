@@ -0,0 +1,274 @@
+// Package source reads and writes KrakenD configuration files in JSON, YAML and TOML,
+// keeping the order of the keys and the literal representation of the numbers
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/pelletier/go-toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// Supported formats
+const (
+	JSON = "json"
+	YAML = "yaml"
+	TOML = "toml"
+)
+
+// Object is a JSON object keeping the order of its members
+type Object []Member
+
+// Member is a key of an object and its value. The values are Object, []interface{},
+// string, json.Number, bool or nil.
+type Member struct {
+	Key   string
+	Value interface{}
+}
+
+// Get returns the value of the key
+func (o Object) Get(key string) (interface{}, bool) {
+	for _, m := range o {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// FormatFromPath returns the format of the file based on its extension, the same way
+// the parser does: JSON is the default one
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return YAML
+	case ".toml":
+		return TOML
+	default:
+		return JSON
+	}
+}
+
+// Decode parses the content in the received format
+func Decode(data []byte, format string) (interface{}, error) {
+	switch format {
+	case JSON:
+		return decodeJSON(data)
+	case YAML:
+		return decodeYAML(data)
+	case TOML:
+		return decodeTOML(data)
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: unexpected content after the top-level value")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		obj := Object{}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, Member{Key: k.(string), Value: v})
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			v, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err = dec.Token()
+		return arr, err
+	default:
+		return t, nil
+	}
+}
+
+func decodeYAML(data []byte) (interface{}, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return Object{}, nil
+	}
+	return fromYAMLNode(doc.Content[0])
+}
+
+func fromYAMLNode(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return fromYAMLNode(n.Alias)
+	case yaml.MappingNode:
+		obj := Object{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				return nil, fmt.Errorf("line %d: YAML merge keys are not supported", k.Line)
+			}
+			val, err := fromYAMLNode(v)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, Member{Key: k.Value, Value: val})
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		arr := make([]interface{}, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := fromYAMLNode(c)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!bool":
+			var b bool
+			err := n.Decode(&b)
+			return b, err
+		case "!!int", "!!float":
+			var v interface{}
+			if err := n.Decode(&v); err != nil {
+				return nil, err
+			}
+			return number(v), nil
+		default:
+			return n.Value, nil
+		}
+	default:
+		return nil, fmt.Errorf("line %d: unsupported YAML node", n.Line)
+	}
+}
+
+func decodeTOML(data []byte) (interface{}, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return fromTOMLTree(tree), nil
+}
+
+// fromTOMLTree converts the tree into an object, sorting the keys by their position
+// in the source
+func fromTOMLTree(tree *toml.Tree) Object {
+	keys := tree.Keys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := tomlPosition(tree, keys[i]), tomlPosition(tree, keys[j])
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+
+	obj := make(Object, 0, len(keys))
+	for _, k := range keys {
+		obj = append(obj, Member{Key: k, Value: fromTOMLValue(tree.GetPath([]string{k}))})
+	}
+	return obj
+}
+
+// tomlPosition returns the position of the key, using the first element of the arrays
+// of tables
+func tomlPosition(tree *toml.Tree, key string) toml.Position {
+	if trees, ok := tree.GetPath([]string{key}).([]*toml.Tree); ok && len(trees) > 0 {
+		return trees[0].Position()
+	}
+	return tree.GetPositionPath([]string{key})
+}
+
+func fromTOMLValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *toml.Tree:
+		return fromTOMLTree(t)
+	case []*toml.Tree:
+		arr := make([]interface{}, len(t))
+		for i, tree := range t {
+			arr[i] = fromTOMLTree(tree)
+		}
+		return arr
+	case []interface{}:
+		arr := make([]interface{}, len(t))
+		for i, e := range t {
+			arr[i] = fromTOMLValue(e)
+		}
+		return arr
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprintf("%v", t)
+	default:
+		return number(v)
+	}
+}
+
+// number converts the numeric values into json.Number, leaving the rest untouched
+func number(v interface{}) interface{} {
+	switch t := v.(type) {
+	case int:
+		return json.Number(strconv.Itoa(t))
+	case int64:
+		return json.Number(strconv.FormatInt(t, 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(t, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(t, 'f', -1, 64))
+	default:
+		return v
+	}
+}
+
+// Parse decodes the value as a service config with the lura parser, so documents
+// coming from different formats can be compared
+func Parse(v interface{}) (config.ServiceConfig, error) {
+	b, err := encodeJSON(v, "")
+	if err != nil {
+		return config.ServiceConfig{}, err
+	}
+	return config.NewParserWithFileReader(func(string) ([]byte, error) {
+		return b, nil
+	}).Parse("")
+}
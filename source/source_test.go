@@ -0,0 +1,380 @@
+package source
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testJSON = `{
+	"version": 3,
+	"endpoints": [
+		{"method": "POST", "endpoint": "/b", "backend": [{"url_pattern": "/b", "host": ["http://b"]}]},
+		{"endpoint": "/a", "timeout": "1s", "extra_config": {"z/ns": {"b": 1.50, "a": true}, "a/ns": null}}
+	],
+	"$schema": "https://www.krakend.io/schema/krakend.json",
+	"custom": {"y": [1, 2], "x": "<&>"},
+	"port": 8080
+}`
+
+func TestCanonical_json(t *testing.T) {
+	v, err := Decode([]byte(testJSON), JSON)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		sortEndpoints bool
+		expected      string
+	}{
+		"keep endpoints": {
+			expected: `{
+  "$schema": "https://www.krakend.io/schema/krakend.json",
+  "version": 3,
+  "port": 8080,
+  "custom": {
+    "x": "<&>",
+    "y": [
+      1,
+      2
+    ]
+  },
+  "endpoints": [
+    {
+      "endpoint": "/b",
+      "method": "POST",
+      "backend": [
+        {
+          "host": [
+            "http://b"
+          ],
+          "url_pattern": "/b"
+        }
+      ]
+    },
+    {
+      "endpoint": "/a",
+      "timeout": "1s",
+      "extra_config": {
+        "a/ns": null,
+        "z/ns": {
+          "a": true,
+          "b": 1.50
+        }
+      }
+    }
+  ]
+}
+`,
+		},
+		"sort endpoints": {
+			sortEndpoints: true,
+			expected: `{
+  "$schema": "https://www.krakend.io/schema/krakend.json",
+  "version": 3,
+  "port": 8080,
+  "custom": {
+    "x": "<&>",
+    "y": [
+      1,
+      2
+    ]
+  },
+  "endpoints": [
+    {
+      "endpoint": "/a",
+      "timeout": "1s",
+      "extra_config": {
+        "a/ns": null,
+        "z/ns": {
+          "a": true,
+          "b": 1.50
+        }
+      }
+    },
+    {
+      "endpoint": "/b",
+      "method": "POST",
+      "backend": [
+        {
+          "host": [
+            "http://b"
+          ],
+          "url_pattern": "/b"
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := Encode(Canonical(v, tc.sortEndpoints), JSON, "  ")
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(b))
+
+			// the canonical form is stable
+			again, err := Decode(b, JSON)
+			require.NoError(t, err)
+			b2, err := Encode(Canonical(again, tc.sortEndpoints), JSON, "  ")
+			require.NoError(t, err)
+			require.Equal(t, string(b), string(b2))
+		})
+	}
+}
+
+func TestLayout_CanonicalYAML(t *testing.T) {
+	in := `# the gateway
+endpoints:
+  - method: post # create
+    endpoint: /b
+    backend: [{url_pattern: /b, host: ["http://b"]}]
+  # the first one
+  - endpoint: /a
+    output_encoding: "json"
+name: 'test' # the name
+version: 3
+`
+	expected := `# the gateway
+version: 3
+name: test # the name
+endpoints:
+  # the first one
+  - endpoint: /a
+    output_encoding: json
+  - endpoint: /b
+    method: post # create
+    backend:
+      - host:
+          - http://b
+        url_pattern: /b
+`
+	b, err := DefaultLayout.CanonicalYAML([]byte(in), true, "  ")
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+
+	again, err := DefaultLayout.CanonicalYAML(b, true, "  ")
+	require.NoError(t, err)
+	require.Equal(t, expected, string(again))
+}
+
+func TestHasTOMLComments(t *testing.T) {
+	for in, expected := range map[string]bool{
+		"version = 3\n":                            false,
+		"version = 3 # the version\n":              true,
+		"# header\nversion = 3\n":                  true,
+		"name = \"#1\"\n":                          false,
+		"name = 'a#b'\n":                           false,
+		"name = \"a\\\"#\"\n":                      false,
+		"name = \"\"\"\n#not a comment\n\"\"\"\n":  false,
+		"name = '''\n#not a comment\n'''\n# yes\n": true,
+	} {
+		require.Equal(t, expected, HasTOMLComments([]byte(in)), in)
+	}
+}
+
+func TestSchemaLayout(t *testing.T) {
+	l, err := SchemaLayout([]byte(`{
+  "$id": "https://example.com/krakend.json",
+  "properties": {
+    "version": {},
+    "timeout": {},
+    "name": {},
+    "endpoints": {"type": "array", "items": {"$ref": "#/definitions/endpoint"}},
+    "extra_config": {"$ref": "service_extra_config.json"}
+  },
+  "definitions": {
+    "endpoint": {
+      "allOf": [
+        {"properties": {"method": {}, "endpoint": {}}},
+        {"properties": {"backend": {"items": {"properties": {"url_pattern": {}, "method": {}}}}}}
+      ]
+    },
+    "service": {
+      "$id": "https://example.com/service_extra_config.json",
+      "properties": {"z": {"properties": {"b": {}, "a": {}}}, "a": {}}
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	v, err := Decode([]byte(`{"name": "n", "version": 3, "extra_config": {"a": 1, "z": {"a": 1, "b": 2}}, "timeout": "1s",
+"endpoints": [{"endpoint": "/a", "backend": [{"method": "GET", "url_pattern": "/a"}], "method": "GET"}]}`), JSON)
+	require.NoError(t, err)
+	b, err := Encode(l.Canonical(v, false), JSON, "")
+	require.NoError(t, err)
+	require.Equal(t, `{"version": 3,"timeout": "1s","name": "n","extra_config": {"z": {"b": 2,"a": 1},"a": 1},`+
+		`"endpoints": [{"method": "GET","endpoint": "/a","backend": [{"url_pattern": "/a","method": "GET"}]}]}`, strings.ReplaceAll(string(b), "\n", ""))
+}
+
+func TestEncode_formats(t *testing.T) {
+	v := Object{
+		{Key: "version", Value: json.Number("3")},
+		{Key: "name", Value: "true"},
+		{Key: "endpoints", Value: []interface{}{
+			Object{
+				{Key: "endpoint", Value: "/a"},
+				{Key: "extra_config", Value: Object{{Key: "qos/ratelimit/router", Value: Object{{Key: "max_rate", Value: json.Number("10")}}}}},
+				{Key: "backend", Value: []interface{}{Object{{Key: "url_pattern", Value: "/x"}, {Key: "host", Value: []interface{}{"http://a"}}}}},
+			},
+		}},
+		{Key: "timeout", Value: "3s"},
+	}
+
+	tests := map[string]string{
+		YAML: `version: 3
+name: "true"
+endpoints:
+  - endpoint: /a
+    extra_config:
+      qos/ratelimit/router:
+        max_rate: 10
+    backend:
+      - url_pattern: /x
+        host:
+          - http://a
+timeout: 3s
+`,
+		TOML: `version = 3
+name = "true"
+timeout = "3s"
+
+[[endpoints]]
+endpoint = "/a"
+
+[endpoints.extra_config."qos/ratelimit/router"]
+max_rate = 10
+
+[[endpoints.backend]]
+url_pattern = "/x"
+host = ["http://a"]
+`,
+	}
+
+	for format, expected := range tests {
+		t.Run(format, func(t *testing.T) {
+			b, err := Encode(v, format, "  ")
+			require.NoError(t, err)
+			require.Equal(t, expected, string(b))
+
+			decoded, err := Decode(b, format)
+			require.NoError(t, err)
+			if format == YAML {
+				require.Equal(t, v, decoded)
+			}
+			again, err := Encode(decoded, format, "  ")
+			require.NoError(t, err)
+			require.Equal(t, expected, string(again))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	v, err := Decode([]byte(`{
+		"version": 3,
+		"port": 8080,
+		"endpoints": [{
+			"endpoint": "/a",
+			"concurrent_calls": 2,
+			"extra_config": {"qos/ratelimit/router": {"max_rate": 10, "every": "1m", "strategy": "ip"}},
+			"backend": [{"url_pattern": "/x", "host": ["http://a"], "extra_config": {"modifier/martian": {"fifo.Group": {"aggregateHeaders": true}}}}]
+		}]
+	}`), JSON)
+	require.NoError(t, err)
+	want, err := Parse(v)
+	require.NoError(t, err)
+
+	for _, format := range []string{YAML, TOML} {
+		b, err := Encode(v, format, "  ")
+		require.NoError(t, err, format)
+		decoded, err := Decode(b, format)
+		require.NoError(t, err, format)
+		have, err := Parse(decoded)
+		require.NoError(t, err, format)
+		require.Equal(t, want.Port, have.Port, format)
+		require.Equal(t, want.Endpoints[0].ConcurrentCalls, have.Endpoints[0].ConcurrentCalls, format)
+		require.Equal(t, want.Endpoints[0].ExtraConfig, have.Endpoints[0].ExtraConfig, format)
+		require.Equal(t, want.Endpoints[0].Backend[0].ExtraConfig, have.Endpoints[0].Backend[0].ExtraConfig, format)
+	}
+}
+
+func TestEncode_tomlNull(t *testing.T) {
+	_, err := Encode(Object{{Key: "a", Value: Object{{Key: "b", Value: nil}}}}, TOML, "")
+	require.EqualError(t, err, "a.b: TOML does not support null values")
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for path, format := range map[string]string{
+		"krakend.json": JSON,
+		"krakend.yml":  YAML,
+		"krakend.YAML": YAML,
+		"krakend.toml": TOML,
+		"krakend.tmpl": JSON,
+	} {
+		require.Equal(t, format, FormatFromPath(path), path)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	for format, tc := range map[string]struct {
+		content  string
+		expected map[string]Position
+	}{
+		JSON: {
+			content: testJSON,
+			expected: map[string]Position{
+				"":                                  {Line: 1, Column: 1},
+				"/endpoints/0":                      {Line: 4, Column: 3},
+				"/endpoints/0/backend/0/host/0":     {Line: 4, Column: 83},
+				"/endpoints/1/extra_config/z~1ns":   {Line: 5, Column: 56},
+				"/endpoints/1/extra_config/z~1ns/a": {Line: 5, Column: 76},
+				"/port":                             {Line: 9, Column: 2},
+			},
+		},
+		YAML: {
+			content: "endpoints:\n  - endpoint: /a\n    extra_config:\n      qos/ratelimit/router:\n        max_rate: 10\n",
+			expected: map[string]Position{
+				"/endpoints/0": {Line: 2, Column: 5},
+				"/endpoints/0/extra_config/qos~1ratelimit~1router": {Line: 4, Column: 7},
+			},
+		},
+		TOML: {
+			content: "port = 8080\n\n[[endpoints]]\nendpoint = \"/a\"\n\n[[endpoints]]\nendpoint = \"/b\"\n[endpoints.extra_config.\"qos/ratelimit/router\"]\nmax_rate = 10\n",
+			expected: map[string]Position{
+				"/port":                 {Line: 1, Column: 1},
+				"/endpoints/1/endpoint": {Line: 7, Column: 1},
+				"/endpoints/1/extra_config/qos~1ratelimit~1router/max_rate": {Line: 9, Column: 1},
+			},
+		},
+	} {
+		t.Run(format, func(t *testing.T) {
+			p, err := Locate([]byte(tc.content), format, "krakend."+format)
+			require.NoError(t, err)
+			for ptr, pos := range tc.expected {
+				pos.File = "krakend." + format
+				require.Equal(t, pos, p[ptr], ptr)
+			}
+		})
+	}
+}
+
+func TestPositions_Lookup(t *testing.T) {
+	p, err := Locate([]byte(testJSON), JSON, "krakend.json")
+	require.NoError(t, err)
+	require.Equal(t, "krakend.json:5:39", p.Lookup(Pointer("endpoints", "1", "extra_config", "auth/validator")).String())
+	require.Equal(t, Position{File: "krakend.json", Line: 1, Column: 1}, p.Lookup("/unknown"))
+}
+
+func TestPositions_Remap(t *testing.T) {
+	partial := "{\"max_rate\": 10}"
+	rendered := "{\n  \"endpoints\": [{\"endpoint\": \"/a\", \"extra_config\": {\"qos/ratelimit/router\": " + partial + "}}]\n}"
+	p, err := Locate([]byte(rendered), JSON, "out.json")
+	require.NoError(t, err)
+	spans := FindSpans([]byte(rendered), map[string][]byte{"partials/rl.json": []byte(partial + "\n")})
+	require.Len(t, spans, 1)
+	p.Remap(spans)
+
+	require.Equal(t, Position{File: "partials/rl.json", Line: 1, Column: 2}, p["/endpoints/0/extra_config/qos~1ratelimit~1router/max_rate"])
+	require.Equal(t, "out.json", p["/endpoints/0/extra_config/qos~1ratelimit~1router"].File)
+}
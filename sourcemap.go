@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/krakend/krakend-cobra/v2/explain"
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Environment variables of the flexible configuration with the directory of the
+// partials and the file where the rendered configuration is written
+const (
+	flexibleConfigPartialsEnv = "FC_PARTIALS"
+	flexibleConfigOutEnv      = "FC_OUT"
+)
+
+// ConfigError is implemented by the errors of CustomValidationFunc referring to an
+// element of the configuration, so they are reported at its position in the source
+type ConfigError interface {
+	error
+	// ConfigPath returns the keys and indexes from the root of the configuration to
+	// the element, like endpoints, 0, backend, 1
+	ConfigPath() []string
+}
+
+// sourceMap locates the elements of a parsed configuration in its source
+type sourceMap struct {
+	positions source.Positions
+}
+
+// newSourceMap locates the elements of the configuration file parsed by p. The file
+// is read from the disk when it can be decoded. Otherwise, like with the templates of
+// the flexible configuration, the elements are located in the content rendered by the
+// parser, and the ones copied from the partials in the partial files. When none of
+// them can be decoded, all the elements are located at the file.
+func newSourceMap(p config.Parser, path string) sourceMap {
+	if raw, err := os.ReadFile(path); err == nil {
+		if positions, err := source.Locate(raw, source.FormatFromPath(path), path); err == nil {
+			return sourceMap{positions: positions}
+		}
+	}
+
+	fallback := sourceMap{positions: source.Positions{"": {File: path}}}
+	data, err := sourceOf(p, path)
+	if err != nil {
+		return fallback
+	}
+	rendered := path
+	if out := os.Getenv(flexibleConfigOutEnv); out != "" {
+		rendered = out
+	}
+	positions, err := source.Locate(data, source.JSON, rendered)
+	if err != nil {
+		return fallback
+	}
+	if dir := os.Getenv(flexibleConfigPartialsEnv); dir != "" {
+		positions.Remap(source.FindSpans(data, readPartials(dir)))
+	}
+	return sourceMap{positions: positions}
+}
+
+// readPartials returns the content of the files in the directory by their path,
+// skipping the ones that cannot be read
+func readPartials(dir string) map[string][]byte {
+	partials := map[string][]byte{}
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if b, err := os.ReadFile(path); err == nil {
+			partials[path] = b
+		}
+		return nil
+	})
+	return partials
+}
+
+// position returns the position of the value at the path of keys and indexes, or the
+// one of its closest ancestor
+func (m sourceMap) position(path ...string) source.Position {
+	return m.positions.Lookup(source.Pointer(path...))
+}
+
+// pointer returns the path to the element at the location of the findings: an
+// endpoint, an async agent, one of their backends or the service when empty
+func pointer(cfg config.ServiceConfig, location string) []string {
+	if location == "" {
+		return nil
+	}
+	for i, e := range cfg.Endpoints {
+		parent := policy.EndpointLocation(e)
+		if parent == location {
+			return []string{"endpoints", strconv.Itoa(i)}
+		}
+		for j, b := range e.Backend {
+			if policy.BackendLocation(parent, b) == location {
+				return []string{"endpoints", strconv.Itoa(i), "backend", strconv.Itoa(j)}
+			}
+		}
+	}
+	for i, a := range cfg.AsyncAgents {
+		parent := policy.AgentLocation(a)
+		if parent == location {
+			return []string{"async_agent", strconv.Itoa(i)}
+		}
+		for j, b := range a.Backend {
+			if policy.BackendLocation(parent, b) == location {
+				return []string{"async_agent", strconv.Itoa(i), "backend", strconv.Itoa(j)}
+			}
+		}
+	}
+	return nil
+}
+
+// finding returns the position of the element violating the rule, pointing to the
+// namespace checked by the rule when the element declares it
+func (m sourceMap) finding(cfg config.ServiceConfig, f policy.Finding, rules []policy.Rule) source.Position {
+	path := pointer(cfg, f.Location)
+	namespace := ""
+	for _, r := range rules {
+		if r.ID == f.Rule {
+			namespace = r.Namespace()
+		}
+	}
+	// the built-in rules about the service point to their namespace
+	if e, ok := explain.Catalog[f.Rule]; ok && f.Location == "" && e.Scope == policy.ScopeService {
+		namespace = e.Namespace
+	}
+	if namespace != "" {
+		path = append(path, "extra_config", namespace)
+	}
+	return m.position(path...)
+}
+
+// configError returns the position of the element of the configuration the error of
+// CustomValidationFunc refers to, or the one of the file
+func (m sourceMap) configError(err error) source.Position {
+	var ce ConfigError
+	if errors.As(err, &ce) {
+		return m.position(ce.ConfigPath()...)
+	}
+	return source.Position{File: m.positions[""].File}
+}
+
+// schemaErrors returns the errors of the validation against the schema with their
+// positions
+func (m sourceMap) schemaErrors(err *jsonschema.ValidationError) []locatedError {
+	if len(err.Causes) == 0 {
+		return []locatedError{{Position: m.position(err.InstanceLocation...), err: err}}
+	}
+	var res []locatedError
+	for _, c := range err.Causes {
+		res = append(res, m.schemaErrors(c)...)
+	}
+	return res
+}
+
+// locatedError is an error with the position of the element of the configuration
+// causing it
+type locatedError struct {
+	source.Position
+	err error
+}
+
+func (e locatedError) Error() string {
+	return e.Position.String() + ": " + e.err.Error()
+}
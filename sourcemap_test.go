@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krakend/krakend-cobra/v2/policy"
+	"github.com/krakend/krakend-cobra/v2/source"
+	"github.com/luraproject/lura/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+type renderedParser struct {
+	rendered []byte
+}
+
+func (renderedParser) Parse(string) (config.ServiceConfig, error) { return config.ServiceConfig{}, nil }
+
+func (p renderedParser) LastSource() ([]byte, error) { return p.rendered, nil }
+
+type testConfigError []string
+
+func (testConfigError) Error() string { return "boom" }
+
+func (e testConfigError) ConfigPath() []string { return e }
+
+func Test_sourceMap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "krakend.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`version: 3
+extra_config:
+  router:
+    hide_version_header: false
+endpoints:
+  - endpoint: /orders
+    method: POST
+    extra_config:
+      qos/ratelimit/router:
+        max_rate: 10
+    backend:
+      - url_pattern: /orders
+`), 0o600))
+
+	cfg := config.ServiceConfig{Endpoints: []*config.EndpointConfig{{
+		Endpoint:    "/orders",
+		Method:      "POST",
+		ExtraConfig: config.ExtraConfig{"qos/ratelimit/router": map[string]interface{}{"max_rate": 10}},
+		Backend:     []*config.Backend{{URLPattern: "/orders"}},
+	}}}
+	rulesPath := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(`rules:
+  - id: ORG-003
+    severity: MEDIUM
+    message: m
+    assert: [{path: extra_config.qos/ratelimit/router.max_rate, op: gt, value: 100}]
+`), 0o600))
+	rules, err := policy.Load(rulesPath)
+	require.NoError(t, err)
+
+	sm := newSourceMap(nil, path)
+	for _, tc := range []struct {
+		finding  policy.Finding
+		expected string
+	}{
+		{finding: policy.Finding{Rule: "ORG-003", Location: "POST /orders"}, expected: path + ":9:7"},
+		{finding: policy.Finding{Rule: "ORG-004", Location: "POST /orders -> /orders"}, expected: path + ":12:9"},
+		{finding: policy.Finding{Rule: "2.2.1"}, expected: path + ":3:3"},
+		{finding: policy.Finding{Rule: "1.1.1"}, expected: path + ":1:1"},
+	} {
+		require.Equal(t, tc.expected, sm.finding(cfg, tc.finding, rules).String(), tc.finding.Rule)
+	}
+
+	require.Equal(t, path+":6:5", sm.configError(testConfigError{"endpoints", "0"}).String())
+	require.Equal(t, path, sm.configError(errors.New("boom")).String())
+}
+
+func Test_sourceMap_partials(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "krakend.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"endpoints": [{{ include "rl.json" }}]}`), 0o600))
+	partial := filepath.Join(dir, "partials", "rl.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(partial), 0o700))
+	require.NoError(t, os.WriteFile(partial, []byte("{\n  \"endpoint\": \"/a\"\n}\n"), 0o600))
+	t.Setenv(flexibleConfigPartialsEnv, filepath.Dir(partial))
+	t.Setenv(flexibleConfigOutEnv, filepath.Join(dir, "out.json"))
+
+	sm := newSourceMap(renderedParser{rendered: []byte("{\"endpoints\": [{\n  \"endpoint\": \"/a\"\n}]}")}, path)
+	require.Equal(t, source.Position{File: partial, Line: 2, Column: 3}, sm.position("endpoints", "0", "endpoint"))
+	require.Equal(t, source.Position{File: filepath.Join(dir, "out.json"), Line: 1, Column: 2}, sm.position("endpoints"))
+}
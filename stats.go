@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/krakend/krakend-cobra/v2/stats"
+	"github.com/spf13/cobra"
+)
+
+func statsFunc(cmd *cobra.Command, _ []string) {
+	if cfgFile == "" {
+		cmd.Println(errorMsg("Please, provide the path to the configuration file with --config or see all the options with --help"))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	cfg, err := parser.Parse(cfgFile)
+	if err != nil {
+		cmd.Println(errorMsg("ERROR parsing the configuration file:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	report := stats.New(cfg)
+
+	switch statsOutput {
+	case "", "text":
+		err = report.Text(cmd.OutOrStdout(), checkDumpPrefix)
+	case "json":
+		var b []byte
+		if b, err = json.MarshalIndent(report, "", "  "); err == nil {
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		}
+	default:
+		err = fmt.Errorf("unknown output format %s", statsOutput)
+	}
+	if err != nil {
+		cmd.Println(errorMsg("ERROR rendering the stats:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+
+	errs := report.Check(stats.Thresholds{
+		MaxEndpoints:     statsMaxEndpoints,
+		MaxBackends:      statsMaxBackends,
+		MaxAmplification: statsAmplification,
+		MaxTimeout:       statsMaxTimeout,
+		RequireAuth:      statsRequireAuth,
+	})
+	if len(errs) > 0 {
+		cmd.Println(errorMsg(fmt.Sprintf("%d threshold(s) exceeded:", len(errs))))
+		for _, err := range errs {
+			cmd.Printf("\t%s\n", err.Error())
+		}
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+}
@@ -0,0 +1,286 @@
+// Package stats computes aggregate metrics of a KrakenD configuration to keep an eye
+// on its size and complexity
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+)
+
+// AuthNamespaces are the extra_config namespaces protecting an endpoint. Embedders can
+// extend the list before computing the reports.
+var AuthNamespaces = []string{
+	"auth/validator",
+	"auth/api-keys",
+	"auth/basic",
+}
+
+// Top is the number of elements listed in the rankings of the report
+var Top = 5
+
+// Report contains the metrics of a configuration
+type Report struct {
+	Endpoints       int             `json:"endpoints"`
+	AsyncAgents     int             `json:"async_agents"`
+	Methods         map[string]int  `json:"methods"`
+	Backends        Distribution    `json:"backends_per_endpoint"`
+	Hosts           []string        `json:"hosts"`
+	Namespaces      map[string]int  `json:"endpoints_per_namespace"`
+	Unauthenticated []string        `json:"unauthenticated_endpoints"`
+	TimeoutChains   []TimeoutChain  `json:"timeout_chains"`
+	Amplification   []Amplification `json:"amplification"`
+}
+
+// Distribution summarizes a set of counts
+type Distribution struct {
+	Total int     `json:"total"`
+	Max   int     `json:"max"`
+	Avg   float64 `json:"avg"`
+	P95   int     `json:"p95"`
+}
+
+// TimeoutChain is the time an endpoint can wait for its backends. With sequential
+// proxies, the backends are called one after the other within the same timeout.
+type TimeoutChain struct {
+	Endpoint   string        `json:"endpoint"`
+	Timeout    time.Duration `json:"timeout"`
+	Backends   int           `json:"backends"`
+	Sequential bool          `json:"sequential"`
+}
+
+// MarshalJSON encodes the timeout as a duration string
+func (c TimeoutChain) MarshalJSON() ([]byte, error) {
+	type alias TimeoutChain
+	return json.Marshal(struct {
+		alias
+		Timeout string `json:"timeout"`
+	}{alias: alias(c), Timeout: c.Timeout.String()})
+}
+
+// Amplification is the number of backend requests triggered by a single request to
+// the endpoint
+type Amplification struct {
+	Endpoint        string `json:"endpoint"`
+	ConcurrentCalls int    `json:"concurrent_calls"`
+	Backends        int    `json:"backends"`
+	Factor          int    `json:"factor"`
+}
+
+// Thresholds are the limits a report must respect. The zero values disable the checks.
+type Thresholds struct {
+	MaxEndpoints     int
+	MaxBackends      int
+	MaxAmplification int
+	MaxTimeout       time.Duration
+	RequireAuth      bool
+}
+
+// New computes the report of the configuration
+func New(cfg config.ServiceConfig) Report {
+	r := Report{
+		Endpoints:       len(cfg.Endpoints),
+		AsyncAgents:     len(cfg.AsyncAgents),
+		Methods:         map[string]int{},
+		Hosts:           []string{},
+		Namespaces:      map[string]int{},
+		Unauthenticated: []string{},
+		TimeoutChains:   []TimeoutChain{},
+		Amplification:   []Amplification{},
+	}
+
+	hosts := map[string]struct{}{}
+	counts := make([]int, 0, len(cfg.Endpoints))
+	for _, e := range cfg.Endpoints {
+		name := e.Method + " " + e.Endpoint
+		r.Methods[e.Method]++
+		counts = append(counts, len(e.Backend))
+
+		namespaces := map[string]struct{}{}
+		for ns := range e.ExtraConfig {
+			namespaces[ns] = struct{}{}
+		}
+		for _, b := range e.Backend {
+			for _, h := range b.Host {
+				hosts[h] = struct{}{}
+			}
+			for ns := range b.ExtraConfig {
+				namespaces[ns] = struct{}{}
+			}
+		}
+		for ns := range namespaces {
+			r.Namespaces[ns]++
+		}
+		if !hasAuth(e.ExtraConfig) {
+			r.Unauthenticated = append(r.Unauthenticated, name)
+		}
+
+		r.TimeoutChains = append(r.TimeoutChains, TimeoutChain{
+			Endpoint:   name,
+			Timeout:    e.Timeout,
+			Backends:   len(e.Backend),
+			Sequential: isSequential(e.ExtraConfig),
+		})
+
+		calls := e.ConcurrentCalls
+		if calls < 1 {
+			calls = 1
+		}
+		r.Amplification = append(r.Amplification, Amplification{
+			Endpoint:        name,
+			ConcurrentCalls: calls,
+			Backends:        len(e.Backend),
+			Factor:          calls * len(e.Backend),
+		})
+	}
+	for _, a := range cfg.AsyncAgents {
+		for _, b := range a.Backend {
+			for _, h := range b.Host {
+				hosts[h] = struct{}{}
+			}
+		}
+	}
+
+	for h := range hosts {
+		r.Hosts = append(r.Hosts, h)
+	}
+	sort.Strings(r.Hosts)
+	r.Backends = distribution(counts)
+
+	sort.SliceStable(r.TimeoutChains, func(i, j int) bool {
+		a, b := r.TimeoutChains[i], r.TimeoutChains[j]
+		if a.Timeout != b.Timeout {
+			return a.Timeout > b.Timeout
+		}
+		return a.Backends > b.Backends
+	})
+	sort.SliceStable(r.Amplification, func(i, j int) bool {
+		return r.Amplification[i].Factor > r.Amplification[j].Factor
+	})
+	if len(r.TimeoutChains) > Top {
+		r.TimeoutChains = r.TimeoutChains[:Top]
+	}
+	if len(r.Amplification) > Top {
+		r.Amplification = r.Amplification[:Top]
+	}
+	return r
+}
+
+func hasAuth(cfg config.ExtraConfig) bool {
+	for _, ns := range AuthNamespaces {
+		if _, ok := cfg[ns]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isSequential(cfg config.ExtraConfig) bool {
+	p, ok := cfg["proxy"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	seq, _ := p["sequential"].(bool)
+	return seq
+}
+
+func distribution(counts []int) Distribution {
+	if len(counts) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]int{}, counts...)
+	sort.Ints(sorted)
+
+	d := Distribution{Max: sorted[len(sorted)-1]}
+	for _, c := range sorted {
+		d.Total += c
+	}
+	d.Avg = math.Round(float64(d.Total)/float64(len(sorted))*100) / 100
+	// nearest-rank percentile
+	d.P95 = sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+	return d
+}
+
+// Check returns the thresholds exceeded by the report
+func (r Report) Check(t Thresholds) []error {
+	var errs []error
+	if t.MaxEndpoints > 0 && r.Endpoints > t.MaxEndpoints {
+		errs = append(errs, fmt.Errorf("%d endpoints defined, the maximum is %d", r.Endpoints, t.MaxEndpoints))
+	}
+	if t.MaxBackends > 0 && r.Backends.Max > t.MaxBackends {
+		errs = append(errs, fmt.Errorf("an endpoint has %d backends, the maximum is %d", r.Backends.Max, t.MaxBackends))
+	}
+	if t.MaxAmplification > 0 && len(r.Amplification) > 0 && r.Amplification[0].Factor > t.MaxAmplification {
+		a := r.Amplification[0]
+		errs = append(errs, fmt.Errorf("%s has an amplification factor of %d, the maximum is %d", a.Endpoint, a.Factor, t.MaxAmplification))
+	}
+	if t.MaxTimeout > 0 && len(r.TimeoutChains) > 0 && r.TimeoutChains[0].Timeout > t.MaxTimeout {
+		c := r.TimeoutChains[0]
+		errs = append(errs, fmt.Errorf("%s has a timeout of %s, the maximum is %s", c.Endpoint, c.Timeout, t.MaxTimeout))
+	}
+	if t.RequireAuth && len(r.Unauthenticated) > 0 {
+		errs = append(errs, fmt.Errorf("%d endpoint(s) without authentication", len(r.Unauthenticated)))
+	}
+	return errs
+}
+
+// Text writes the report in a human readable format
+func (r Report) Text(w io.Writer, prefix string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Endpoints: %d\n", r.Endpoints)
+	fmt.Fprintf(&sb, "Async agents: %d\n", r.AsyncAgents)
+
+	sb.WriteString("Endpoints by method:\n")
+	for _, m := range sortedKeys(r.Methods) {
+		fmt.Fprintf(&sb, "%s%s: %d\n", prefix, m, r.Methods[m])
+	}
+
+	fmt.Fprintf(&sb, "Backends per endpoint: total %d, max %d, avg %g, p95 %d\n", r.Backends.Total, r.Backends.Max, r.Backends.Avg, r.Backends.P95)
+
+	fmt.Fprintf(&sb, "Distinct backend hosts: %d\n", len(r.Hosts))
+	for _, h := range r.Hosts {
+		fmt.Fprintf(&sb, "%s%s\n", prefix, h)
+	}
+
+	sb.WriteString("Endpoints per namespace:\n")
+	for _, ns := range sortedKeys(r.Namespaces) {
+		fmt.Fprintf(&sb, "%s%s: %d\n", prefix, ns, r.Namespaces[ns])
+	}
+
+	fmt.Fprintf(&sb, "Endpoints without authentication: %d\n", len(r.Unauthenticated))
+	for _, e := range r.Unauthenticated {
+		fmt.Fprintf(&sb, "%s%s\n", prefix, e)
+	}
+
+	sb.WriteString("Longest timeout chains:\n")
+	for _, c := range r.TimeoutChains {
+		mode := "parallel"
+		if c.Sequential {
+			mode = "sequential"
+		}
+		fmt.Fprintf(&sb, "%s%s: %s, %d %s backend(s)\n", prefix, c.Endpoint, c.Timeout, c.Backends, mode)
+	}
+
+	sb.WriteString("Highest amplification factors:\n")
+	for _, a := range r.Amplification {
+		fmt.Fprintf(&sb, "%s%s: x%d (%d concurrent call(s) to %d backend(s))\n", prefix, a.Endpoint, a.Factor, a.ConcurrentCalls, a.Backends)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
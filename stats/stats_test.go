@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luraproject/lura/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cfg := config.ServiceConfig{
+		Endpoints: []*config.EndpointConfig{
+			{
+				Endpoint:        "/a",
+				Method:          "GET",
+				Timeout:         3 * time.Second,
+				ConcurrentCalls: 1,
+				ExtraConfig:     config.ExtraConfig{"auth/validator": map[string]interface{}{}},
+				Backend: []*config.Backend{
+					{URLPattern: "/x", Host: []string{"http://a"}},
+					{URLPattern: "/y", Host: []string{"http://b"}},
+				},
+			},
+			{
+				Endpoint:        "/b",
+				Method:          "POST",
+				Timeout:         10 * time.Second,
+				ConcurrentCalls: 3,
+				ExtraConfig:     config.ExtraConfig{"proxy": map[string]interface{}{"sequential": true}},
+				Backend: []*config.Backend{
+					{URLPattern: "/x", Host: []string{"http://a"}},
+					{URLPattern: "/z", Host: []string{"http://a"}, ExtraConfig: config.ExtraConfig{"qos/ratelimit/proxy": nil}},
+				},
+			},
+			{
+				Endpoint: "/c",
+				Method:   "GET",
+				Timeout:  3 * time.Second,
+				Backend:  []*config.Backend{{URLPattern: "/x", Host: []string{"http://c"}}},
+			},
+		},
+		AsyncAgents: []*config.AsyncAgent{
+			{Name: "agent", Backend: []*config.Backend{{URLPattern: "/x", Host: []string{"http://d"}}}},
+		},
+	}
+
+	r := New(cfg)
+	require.Equal(t, 3, r.Endpoints)
+	require.Equal(t, 1, r.AsyncAgents)
+	require.Equal(t, map[string]int{"GET": 2, "POST": 1}, r.Methods)
+	require.Equal(t, Distribution{Total: 5, Max: 2, Avg: 1.67, P95: 2}, r.Backends)
+	require.Equal(t, []string{"http://a", "http://b", "http://c", "http://d"}, r.Hosts)
+	require.Equal(t, map[string]int{"auth/validator": 1, "proxy": 1, "qos/ratelimit/proxy": 1}, r.Namespaces)
+	require.Equal(t, []string{"POST /b", "GET /c"}, r.Unauthenticated)
+	require.Equal(t, []TimeoutChain{
+		{Endpoint: "POST /b", Timeout: 10 * time.Second, Backends: 2, Sequential: true},
+		{Endpoint: "GET /a", Timeout: 3 * time.Second, Backends: 2},
+		{Endpoint: "GET /c", Timeout: 3 * time.Second, Backends: 1},
+	}, r.TimeoutChains)
+	require.Equal(t, Amplification{Endpoint: "POST /b", ConcurrentCalls: 3, Backends: 2, Factor: 6}, r.Amplification[0])
+
+	tests := map[string]struct {
+		thresholds Thresholds
+		errs       []string
+	}{
+		"disabled": {},
+		"within limits": {
+			thresholds: Thresholds{MaxEndpoints: 3, MaxBackends: 2, MaxAmplification: 6, MaxTimeout: 10 * time.Second},
+		},
+		"exceeded": {
+			thresholds: Thresholds{MaxEndpoints: 2, MaxBackends: 1, MaxAmplification: 4, MaxTimeout: 5 * time.Second, RequireAuth: true},
+			errs: []string{
+				"3 endpoints defined, the maximum is 2",
+				"an endpoint has 2 backends, the maximum is 1",
+				"POST /b has an amplification factor of 6, the maximum is 4",
+				"POST /b has a timeout of 10s, the maximum is 5s",
+				"2 endpoint(s) without authentication",
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var errs []string
+			for _, err := range r.Check(tc.thresholds) {
+				errs = append(errs, err.Error())
+			}
+			require.Equal(t, tc.errs, errs)
+		})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Text(&buf, "  "))
+	require.Contains(t, buf.String(), "Backends per endpoint: total 5, max 2, avg 1.67, p95 2\n")
+	require.Contains(t, buf.String(), "  POST /b: 10s, 2 sequential backend(s)\n")
+	require.Contains(t, buf.String(), "  POST /b: x6 (3 concurrent call(s) to 2 backend(s))\n")
+
+	b, err := json.Marshal(r.TimeoutChains[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"endpoint":"POST /b","timeout":"10s","backends":2,"sequential":true}`, string(b))
+}
+
+func TestNew_empty(t *testing.T) {
+	r := New(config.ServiceConfig{})
+	require.Equal(t, Distribution{}, r.Backends)
+	require.Empty(t, r.Check(Thresholds{MaxBackends: 1, MaxTimeout: time.Second, RequireAuth: true}))
+}
@@ -1,12 +1,36 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+
 	"github.com/luraproject/lura/v2/core"
 	"github.com/spf13/cobra"
 )
 
 func versionFunc(cmd *cobra.Command, _ []string) {
-	cmd.Println("KrakenD Version:", core.KrakendVersion)
-	cmd.Println("Go Version:", core.GoVersion)
-	cmd.Println("Glibc Version:", core.GlibcVersion)
+	switch versionOutput {
+	case "json":
+		describeFunc(cmd, nil)
+	case "", "text":
+		cmd.Println("KrakenD Version:", core.KrakendVersion)
+		cmd.Println("Go Version:", core.GoVersion)
+		cmd.Println("Glibc Version:", core.GlibcVersion)
+	default:
+		cmd.Println(errorMsg(fmt.Sprintf("ERROR unknown output format: %s", versionOutput)))
+		os.Exit(1) // skipcq: RVV-A0003
+	}
+}
+
+// describeFunc prints the descriptor of the binary, so plugins can be checked
+// against it on machines without the binary
+func describeFunc(cmd *cobra.Command, _ []string) {
+	b, err := json.MarshalIndent(localDescriber(), "", "  ")
+	if err != nil {
+		cmd.Println(errorMsg("ERROR describing the binary:") + fmt.Sprintf("\t%s\n", err.Error()))
+		os.Exit(1) // skipcq: RVV-A0003
+		return
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(b))
 }